@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand(opts *Options) *cobra.Command {
+	cmd := groupCommand(&cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Print the merged configuration as YAML, secrets redacted",
+		Long: `Print the merged configuration as YAML, secrets redacted.
+
+The output is a valid config file: with the secrets filled back in it can be
+passed to --config.`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Show(cmd.OutOrStdout())
+		},
+	})
+	return cmd
+}
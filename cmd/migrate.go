@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hexlet-components/go-gin-example/db"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand(opts *Options) *cobra.Command {
+	var dryRun bool
+
+	cmd := groupCommand(&cobra.Command{
+		Use:   "migrate",
+		Short: "Run database migrations",
+	})
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&opts.Migrations.Dir, "dir", "", "same as --migrations-dir")
+	flags.BoolVar(&dryRun, "dry-run", false, "print the SQL that would run without touching the database")
+	flags.StringVar(&opts.Migrations.BackupsDir, "backups-dir", opts.Migrations.BackupsDir, "where backups go, relative to the database directory")
+	flags.IntVar(&opts.Migrations.KeepBackups, "keep-backups", opts.Migrations.KeepBackups, "how many of the newest backups to keep, 0 keeps all")
+	flags.BoolVar(&opts.Migrations.NoBackup, "no-backup", false, "do not back up the database before down, down-to, redo, reset and restore")
+
+	options := func(cmd *cobra.Command) *db.MigrationOptions {
+		migrationOpts := migrationOptions(opts)
+		migrationOpts.DryRun = dryRun
+		migrationOpts.Out = cmd.OutOrStdout()
+		return migrationOpts
+	}
+	// completed reports success, a dry run has already printed its plan
+	completed := func(cmd *cobra.Command, args ...any) {
+		if !dryRun {
+			fmt.Fprintln(cmd.OutOrStdout(), append([]any{"Migration command completed:"}, args...)...)
+		}
+	}
+
+	// simple covers the subcommands that take no arguments
+	simple := func(use, short string, migrate func(*db.MigrationOptions) error) *cobra.Command {
+		return &cobra.Command{
+			Use:   use,
+			Short: short,
+			Args:  usageArgs(cobra.NoArgs),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if err := migrate(options(cmd)); err != nil {
+					return err
+				}
+				completed(cmd, use)
+				return nil
+			},
+		}
+	}
+
+	// versioned covers the subcommands that take a target version
+	versioned := func(use, short string, migrate func(*db.MigrationOptions, int64) error) *cobra.Command {
+		return &cobra.Command{
+			Use:   use + " <version>",
+			Short: short,
+			Args:  usageArgs(cobra.ExactArgs(1)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				version, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil || version < 0 {
+					return usageError{fmt.Errorf("invalid version: %s", args[0])}
+				}
+				if err := migrate(options(cmd), version); err != nil {
+					return err
+				}
+				completed(cmd, use, version)
+				return nil
+			},
+		}
+	}
+
+	cmd.AddCommand(
+		simple("up", "Apply all pending migrations", db.MigrateUp),
+		versioned("up-to", "Apply the pending migrations up to the given version", db.MigrateUpTo),
+		simple("down", "Roll back the last migration", db.MigrateDown),
+		versioned("down-to", "Roll back the migrations above the given version", db.MigrateDownTo),
+		simple("redo", "Roll back the last migration and apply it again", db.MigrateRedo),
+		simple("status", "Show which migrations are applied", db.MigrateStatus),
+		simple("reset", "Delete the database and migrate it from scratch", db.MigrateReset),
+		versioned("to", "Migrate up or down to the given version", db.MigrateTo),
+		&cobra.Command{
+			Use:   "version",
+			Short: "Print the current schema version",
+			Args:  usageArgs(cobra.NoArgs),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				version, err := db.MigrateVersion(options(cmd))
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Current version:", version)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "backup",
+			Short: "Copy the database into the backups directory",
+			Args:  usageArgs(cobra.NoArgs),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				path, err := db.MigrateBackup(options(cmd))
+				if err != nil {
+					return err
+				}
+				if path == "" {
+					return fmt.Errorf("database file does not exist: %s", opts.DB.Path)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Backup saved to", path)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "restore <backup>",
+			Short: "Replace the database with a backup, given as a path or a name in the backups directory",
+			Args:  usageArgs(cobra.ExactArgs(1)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if err := db.MigrateRestore(options(cmd), args[0]); err != nil {
+					return err
+				}
+				completed(cmd, "restore", args[0])
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:       "create <name> [sql|go]",
+			Short:     "Create a blank migration, SQL unless go is given",
+			Args:      usageArgs(cobra.RangeArgs(1, 2)),
+			ValidArgs: []string{"sql", "go"},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				migrationType := "sql"
+				if len(args) == 2 {
+					migrationType = args[1]
+				}
+				if migrationType != "sql" && migrationType != "go" {
+					return usageError{fmt.Errorf("migration type must be sql or go, got %s", migrationType)}
+				}
+				path, err := db.MigrateCreate(options(cmd), args[0], migrationType)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Created", path)
+				return nil
+			},
+		},
+	)
+	return cmd
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hexlet-components/go-gin-example/config"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/spf13/cobra"
+)
+
+// retentionValue is a duration flag parsed with config.ParseDuration
+type retentionValue time.Duration
+
+func (v *retentionValue) String() string {
+	return time.Duration(*v).String()
+}
+
+func (v *retentionValue) Set(s string) error {
+	d, err := config.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = retentionValue(d)
+	return nil
+}
+
+func (v *retentionValue) Type() string {
+	return "duration"
+}
+
+func newPurgeCommand(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete articles that have been in the trash for longer than the retention window",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, err := openDB(opts)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			// Удаляем навсегда статьи, которые лежат в корзине дольше срока хранения
+			before := time.Now().UTC().Add(-opts.Purge.Retention)
+			purged, err := store.New(opts.DB.Driver, database, nil).PurgeDeletedArticles(cmd.Context(), &before)
+			if err != nil {
+				return fmt.Errorf("failed to purge deleted articles: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Purged %d article(s) deleted before %s\n", purged, before.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	cmd.Flags().Var((*retentionValue)(&opts.Purge.Retention), "retention", "purge articles deleted longer ago than this, e.g. 30d or 12h")
+	return cmd
+}
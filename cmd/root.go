@@ -0,0 +1,239 @@
+// Package cmd is the command tree of the application binary
+package cmd
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/hexlet-components/go-gin-example/config"
+	"github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/internal/logging"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Exit codes shared by every subcommand
+const (
+	ExitOK    = 0
+	ExitError = 1
+	// ExitUsage means the command line itself was wrong: an unknown command
+	// or flag, or the wrong number of arguments
+	ExitUsage = 2
+)
+
+// Options holds the configuration every subcommand reads, the flags are
+// bound to its fields
+type Options struct {
+	config.Config
+	// ConfigFile is the YAML or TOML file loaded under the environment and
+	// the flags
+	ConfigFile string
+}
+
+// load merges the configuration: defaults, then the config file, then the
+// APP_* environment and finally the flags given on the command line
+func (o *Options) load(cmd *cobra.Command) error {
+	type flagValue struct {
+		flag  *pflag.Flag
+		value string
+	}
+	var changed []flagValue
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		changed = append(changed, flagValue{f, f.Value.String()})
+	})
+
+	cfg := config.Default()
+	if path := cmp.Or(o.ConfigFile, os.Getenv(configEnv)); path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return usageError{err}
+		}
+	}
+	if err := cfg.LoadEnv(os.LookupEnv); err != nil {
+		return usageError{err}
+	}
+
+	// Флаги записывают значения прямо в o.Config, поэтому после загрузки
+	// файла и окружения их нужно применить заново
+	o.Config = cfg
+	for _, f := range changed {
+		if err := f.flag.Value.Set(f.value); err != nil {
+			return usageError{fmt.Errorf("invalid argument %q for --%s: %w", f.value, f.flag.Name, err)}
+		}
+	}
+
+	if err := o.Validate(); err != nil {
+		return usageError{fmt.Errorf("invalid configuration: %w", err)}
+	}
+
+	// Логгер по умолчанию получает и записи пакета log, в том числе goose
+	logger, err := logging.New(cmd.ErrOrStderr(), o.Log.Options())
+	if err != nil {
+		return usageError{err}
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// configEnv names the config file when --config is not given
+const configEnv = config.EnvPrefix + "CONFIG"
+
+// usageError marks errors that are the caller's fault, they exit with
+// ExitUsage and print the usage of the command
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// usageArgs wraps an argument validator so that its errors are usage errors
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	}
+}
+
+// groupCommand makes cmd a node of the tree that only holds subcommands.
+// Cobra prints help and succeeds for those by default, which hides typos.
+func groupCommand(cmd *cobra.Command) *cobra.Command {
+	cmd.Args = usageArgs(cobra.NoArgs)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return usageError{fmt.Errorf("%s requires a subcommand", cmd.CommandPath())}
+	}
+	return cmd
+}
+
+func NewRootCommand() *cobra.Command {
+	opts := &Options{Config: config.Default()}
+
+	root := groupCommand(&cobra.Command{
+		Use:   "go-gin-example",
+		Short: "Articles REST API on Gin with SQLite or PostgreSQL",
+		Long: `Articles REST API on Gin with SQLite or PostgreSQL.
+
+Settings come from the defaults, then the --config file, then the APP_*
+environment variables and finally the flags. Run go-gin-example config show
+to see the result.
+
+Exit codes: 0 on success, 1 when the command fails, 2 on invalid usage.`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	})
+	root.CompletionOptions.DisableDefaultCmd = true
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.ConfigFile, "config", "", "YAML or TOML config file (default $"+configEnv+")")
+	flags.StringVar(&opts.DB.Driver, "db-driver", opts.DB.Driver, "database to use: sqlite3 or postgres")
+	flags.StringVar(&opts.DB.Path, "db", opts.DB.Path, "path to SQLite database file")
+	flags.StringVar(&opts.DB.DSN, "dsn", "", "PostgreSQL connection string (default $APP_DB_DSN or $DATABASE_URL)")
+	flags.StringVar(&opts.Migrations.Dir, "migrations-dir", "", "read migrations from this directory instead of the embedded ones")
+	flags.IntVar(&opts.DB.MaxOpenConns, "db-max-open-conns", opts.DB.MaxOpenConns, "connection limit, of the readers for SQLite (default one per CPU for SQLite, unlimited for PostgreSQL)")
+	flags.DurationVar(&opts.DB.ConnMaxLifetime, "db-conn-max-lifetime", opts.DB.ConnMaxLifetime, "close connections after this long, 0 keeps them")
+	flags.DurationVar(&opts.DB.BusyTimeout, "db-busy-timeout", opts.DB.BusyTimeout, "how long SQLite waits for a lock held by another connection")
+	flags.StringVar(&opts.Log.Level, "log-level", opts.Log.Level, "least severe level logged: debug, info, warn or error")
+	flags.StringVar(&opts.Log.Format, "log-format", opts.Log.Format, "log as text for reading or json for log collectors")
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return opts.load(cmd)
+	}
+
+	root.AddCommand(
+		newServeCommand(opts),
+		newMigrateCommand(opts),
+		newSeedCommand(opts),
+		newPurgeCommand(opts),
+		newConfigCommand(opts),
+		newVersionCommand(),
+	)
+	return root
+}
+
+// Execute runs the process command line and returns the exit code
+func Execute() int {
+	return Run(os.Args[1:], os.Stdout, os.Stderr)
+}
+
+// Run runs a command line with the given output streams and returns the exit
+// code
+func Run(args []string, stdout, stderr io.Writer) int {
+	// Команда подменяет логгер по умолчанию, Run возвращает прежний, чтобы
+	// несколько запусков в одном процессе не писали в чужой stderr
+	defer restoreLogger(slog.Default(), log.Writer(), log.Flags())
+
+	root := NewRootCommand()
+	root.SetArgs(args)
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+
+	cmd, err := root.ExecuteC()
+	if err == nil {
+		return ExitOK
+	}
+
+	fmt.Fprintln(stderr, "Error:", err)
+	var usage usageError
+	if errors.As(err, &usage) {
+		fmt.Fprintln(stderr)
+		fmt.Fprint(stderr, cmd.UsageString())
+		return ExitUsage
+	}
+	return ExitError
+}
+
+func restoreLogger(logger *slog.Logger, w io.Writer, flags int) {
+	slog.SetDefault(logger)
+	log.SetOutput(w)
+	log.SetFlags(flags)
+}
+
+// openDB opens an existing database, a SQLite file is never created
+// implicitly so that a mistyped --db does not end up as an empty database
+func openDB(opts *Options) (*db.Pools, error) {
+	if opts.DB.Driver == db.DialectSQLite {
+		if _, err := os.Stat(opts.DB.Path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("database file does not exist: %s, run migrations first: go-gin-example migrate up", opts.DB.Path)
+		}
+		return db.OpenSQLite(opts.DB.Path, opts.DB.SQLiteOptions())
+	}
+
+	database, err := sql.Open(db.DriverName(opts.DB.Driver), opts.DB.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	opts.DB.SQLiteOptions().PoolOptions.Apply(database)
+	if err := database.Ping(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db.SinglePool(database), nil
+}
+
+func migrationOptions(opts *Options) *db.MigrationOptions {
+	migrationOpts := db.DefaultMigrationOptions()
+	if opts.DB.Driver == db.DialectPostgres {
+		migrationOpts = db.PostgresMigrationOptions(opts.DB.DSN)
+	}
+	migrationOpts.DBFile = opts.DB.Path
+	migrationOpts.BackupsDir = opts.Migrations.BackupsDir
+	migrationOpts.KeepBackups = opts.Migrations.KeepBackups
+	migrationOpts.NoBackup = opts.Migrations.NoBackup
+	if opts.Migrations.Dir != "" {
+		migrationOpts.MigrationsFromDir(opts.Migrations.Dir)
+	}
+	return migrationOpts
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/spf13/cobra"
+)
+
+// seedArticles is sample content for local development
+var seedArticles = []struct {
+	name, body, status string
+}{
+	{"Введение в Go", "Go — компилируемый язык со сборкой мусора и горутинами.", "published"},
+	{"Маршрутизация в Gin", "Группы маршрутов, параметры пути и обработчики.", "published"},
+	{"Миграции с goose", "SQL- и Go-миграции, откат и статус.", "published"},
+	{"Генерация кода с sqlc", "Запросы пишутся на SQL, а типобезопасный Go-код генерируется.", "draft"},
+	{"Testing HTTP handlers", "httptest.NewRecorder and table-driven tests.", "draft"},
+}
+
+func newSeedCommand(opts *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the database with sample articles",
+		Long:  "Fill the database with sample articles. Articles that already exist are skipped, so it is safe to run twice.",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, err := openDB(opts)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			articles := store.New(opts.DB.Driver, database, nil)
+			created := 0
+			for _, article := range seedArticles {
+				slug := textnorm.Slugify(article.name)
+				_, err := articles.CreateArticle(cmd.Context(), db.CreateArticleParams{
+					Name:    article.name,
+					NameKey: textnorm.NameKey(article.name),
+					Body:    article.body,
+					Slug:    &slug,
+					Status:  article.status,
+					Now:     time.Now().UTC(),
+				})
+
+				if store.IsUniqueViolation(err) {
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to seed %q: %w", article.name, err)
+				}
+				created++
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Seeded %d article(s), %d already existed\n", created, len(seedArticles)-created)
+			return nil
+		},
+	}
+}
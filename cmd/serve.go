@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/hexlet-components/go-gin-example/internal/metrics"
+	"github.com/hexlet-components/go-gin-example/internal/server"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand(opts *Options) *cobra.Command {
+	var cfg handlers.RouterConfig
+
+	cmd := &cobra.Command{
+		Use:     "serve",
+		Aliases: []string{"api"},
+		Short:   "Start the API server",
+		Args:    usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			migrationOpts := migrationOptions(opts)
+			if opts.Server.AutoMigrate {
+				if err := db.MigrateUp(migrationOpts); err != nil {
+					return fmt.Errorf("failed to migrate: %w", err)
+				}
+			}
+
+			database, err := openDB(opts)
+			if err != nil {
+				return err
+			}
+			// Закрываем базу один раз: при ошибке запуска здесь, при остановке
+			// после того, как все запросы завершились
+			closeDB := sync.OnceValue(database.Close)
+			defer closeDB()
+
+			// Схема базы должна соответствовать коду
+			cfg.Migrations, err = db.MigrationVersions(migrationOpts)
+			if err != nil {
+				return err
+			}
+			cfg.DB = database.Reader
+			cfg.Dialect = opts.DB.Driver
+			cfg.AdminToken = opts.Server.AdminToken
+			if err := checkSchema(cmd, database.Reader, opts.DB.Driver, cfg.Migrations); err != nil {
+				return err
+			}
+			// Индекс поиска зависит от того, собран ли бинарник с FTS5, а не от
+			// версии схемы
+			if err := db.SyncSearchIndex(cmd.Context(), database.Writer, opts.DB.Driver); err != nil {
+				return err
+			}
+
+			// Настройка роутера, отладочный вывод gin идёт в лог уровня debug
+			gin.DebugPrintFunc = func(format string, values ...any) {
+				slog.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+			}
+			gin.DebugPrintRouteFunc = func(method, path, handler string, _ int) {
+				slog.Debug("Route", slog.String("method", method), slog.String("route", path), slog.String("handler", handler))
+			}
+			var observe store.QueryObserver
+			if opts.Metrics.Enabled {
+				cfg.Metrics = metrics.New()
+				cfg.Metrics.RegisterPools(database)
+				cfg.MetricsRoute = opts.Metrics.Port == 0
+				observe = cfg.Metrics.ObserveQuery
+			}
+			r := handlers.SetupRouter(store.New(opts.DB.Driver, database, observe), cfg)
+
+			// Запуск сервера
+			ln, err := net.Listen("tcp", opts.Server.Addr())
+			if err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+			slog.Info("Starting server", slog.String("url", localURL(ln)))
+
+			// Метрики на отдельном порту, закрытом от клиентов API
+			var adminLn net.Listener
+			if cfg.Metrics != nil && !cfg.MetricsRoute {
+				adminLn, err = net.Listen("tcp", opts.Metrics.Addr())
+				if err != nil {
+					ln.Close()
+					return fmt.Errorf("failed to listen for metrics: %w", err)
+				}
+				slog.Info("Serving metrics", slog.String("url", localURL(adminLn)+"/metrics"))
+			}
+
+			// SIGINT и SIGTERM запускают плавную остановку, повторный сигнал
+			// завершает процесс сразу
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			context.AfterFunc(ctx, stop)
+
+			// Сервер метрик останавливается вместе с основным, в том числе когда
+			// тот упал сам
+			serveCtx, cancel := context.WithCancel(ctx)
+			var wg sync.WaitGroup
+			var adminErr error
+			if adminLn != nil {
+				mux := http.NewServeMux()
+				mux.Handle("GET /metrics", cfg.Metrics.Handler())
+				wg.Go(func() {
+					adminErr = server.Serve(serveCtx, adminLn, mux, opts.Server.Options())
+					cancel()
+				})
+			}
+			err = server.Serve(serveCtx, ln, r, opts.Server.Options())
+			cancel()
+			wg.Wait()
+			err = errors.Join(err, adminErr)
+
+			// База закрывается только после того, как все запросы завершились
+			if closeErr := closeDB(); closeErr != nil {
+				return errors.Join(err, fmt.Errorf("failed to close database: %w", closeErr))
+			}
+			slog.Info("Database closed")
+			return err
+		},
+	}
+
+	defaults := opts.Server
+	flags := cmd.Flags()
+	flags.IntVar(&opts.Server.Port, "port", defaults.Port, "port to run the server on")
+	flags.BoolVar(&opts.Server.AutoMigrate, "auto-migrate", false, "apply pending migrations before starting")
+	flags.StringVar(&opts.Server.AdminToken, "admin-token", "", "bearer token for admin operations (default $APP_SERVER_ADMIN_TOKEN or $ADMIN_TOKEN)")
+	flags.DurationVar(&opts.Server.ReadHeaderTimeout, "read-header-timeout", defaults.ReadHeaderTimeout, "limit for reading request headers, 0 disables it")
+	flags.DurationVar(&opts.Server.ReadTimeout, "read-timeout", defaults.ReadTimeout, "limit for reading a whole request, 0 disables it")
+	flags.DurationVar(&opts.Server.WriteTimeout, "write-timeout", defaults.WriteTimeout, "limit for writing a response, 0 disables it")
+	flags.DurationVar(&opts.Server.IdleTimeout, "idle-timeout", defaults.IdleTimeout, "how long keep-alive connections wait for the next request, 0 disables it")
+	flags.IntVar(&opts.Server.MaxHeaderBytes, "max-header-bytes", defaults.MaxHeaderBytes, "largest request headers accepted")
+	flags.DurationVar(&opts.Server.ShutdownTimeout, "shutdown-timeout", defaults.ShutdownTimeout, "how long in-flight requests get to finish on SIGINT or SIGTERM")
+	flags.BoolVar(&opts.Metrics.Enabled, "metrics", opts.Metrics.Enabled, "serve Prometheus metrics at /metrics")
+	flags.IntVar(&opts.Metrics.Port, "metrics-port", opts.Metrics.Port, "serve /metrics on this port instead of the API one")
+	return cmd
+}
+
+// localURL is how to reach a server listening on ln from the same host
+func localURL(ln net.Listener) string {
+	return fmt.Sprintf("http://localhost:%d", ln.Addr().(*net.TCPAddr).Port)
+}
+
+// checkSchema refuses to serve a database that is behind the migrations of
+// the binary. A database ahead of them is only reported, it is what a
+// rollback to an older binary looks like.
+func checkSchema(cmd *cobra.Command, database *sql.DB, dialect string, versions []int64) error {
+	status, err := db.CheckSchema(cmd.Context(), database, dialect, versions)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if !status.UpToDate() {
+		return fmt.Errorf("database schema is at version %d, %d migration(s) pending up to %d: run go-gin-example migrate up or start with --auto-migrate",
+			status.Version, len(status.Pending), status.Latest)
+	}
+	if status.Ahead() {
+		slog.Warn("Database schema is newer than the latest known migration",
+			slog.Int64("version", status.Version), slog.Int64("latest", status.Latest))
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hexlet-components/go-gin-example/internal/buildinfo"
+	"github.com/spf13/cobra"
+)
+
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of the binary",
+		Args:  usageArgs(cobra.NoArgs),
+		Run: func(cmd *cobra.Command, args []string) {
+			info := buildinfo.Read()
+			commit := info.Commit
+			if info.Modified {
+				commit += "-dirty"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "go-gin-example %s (commit %s, %s)\n", info.Version, commit, info.GoVersion)
+		},
+	}
+}
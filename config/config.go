@@ -0,0 +1,209 @@
+// Package config holds the settings of every command. Each setting has a
+// default that, from lowest to highest precedence, a YAML or TOML file, an
+// APP_* environment variable and a command line flag override.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/internal/logging"
+	"github.com/hexlet-components/go-gin-example/internal/server"
+)
+
+// Config is the merged configuration. The config tags are the keys of the
+// file: db.dsn in YAML or TOML is APP_DB_DSN in the environment. Settings
+// tagged secret are redacted when shown.
+type Config struct {
+	DB         DB         `config:"db"`
+	Server     Server     `config:"server"`
+	Migrations Migrations `config:"migrations"`
+	Purge      Purge      `config:"purge"`
+	Log        Log        `config:"log"`
+	Metrics    Metrics    `config:"metrics"`
+}
+
+type DB struct {
+	// Driver is db.DialectSQLite or db.DialectPostgres
+	Driver string `config:"driver"`
+	// Path is the SQLite database file
+	Path string `config:"path"`
+	// DSN is the PostgreSQL connection string, only its password is secret
+	DSN             string        `config:"dsn" secret:"password"`
+	MaxOpenConns    int           `config:"max_open_conns"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime"`
+	BusyTimeout     time.Duration `config:"busy_timeout"`
+}
+
+type Server struct {
+	Port        int    `config:"port"`
+	AutoMigrate bool   `config:"auto_migrate"`
+	AdminToken  string `config:"admin_token" secret:"true"`
+
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout"`
+	ReadTimeout       time.Duration `config:"read_timeout"`
+	WriteTimeout      time.Duration `config:"write_timeout"`
+	IdleTimeout       time.Duration `config:"idle_timeout"`
+	MaxHeaderBytes    int           `config:"max_header_bytes"`
+	ShutdownTimeout   time.Duration `config:"shutdown_timeout"`
+}
+
+type Migrations struct {
+	// Dir overrides the migrations embedded into the binary
+	Dir         string `config:"dir"`
+	BackupsDir  string `config:"backups_dir"`
+	KeepBackups int    `config:"keep_backups"`
+	NoBackup    bool   `config:"no_backup"`
+}
+
+type Purge struct {
+	Retention time.Duration `config:"retention"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error
+	Level string `config:"level"`
+	// Format is logging.FormatText or logging.FormatJSON
+	Format string `config:"format"`
+}
+
+type Metrics struct {
+	// Enabled exposes GET /metrics in the Prometheus format
+	Enabled bool `config:"enabled"`
+	// Port serves /metrics on a port of its own, e.g. one that only the
+	// monitoring network reaches. Zero serves it on the API port.
+	Port int `config:"port"`
+}
+
+// Default is the configuration before anything overrides it
+func Default() Config {
+	pool := db.DefaultSQLiteOptions()
+	srv := server.DefaultOptions()
+	return Config{
+		DB: DB{
+			Driver:          db.DialectSQLite,
+			Path:            db.DefaultDBFile,
+			MaxOpenConns:    pool.MaxOpenConns,
+			ConnMaxLifetime: pool.ConnMaxLifetime,
+			BusyTimeout:     pool.BusyTimeout,
+		},
+		Server: Server{
+			Port:              8080,
+			ReadHeaderTimeout: srv.ReadHeaderTimeout,
+			ReadTimeout:       srv.ReadTimeout,
+			WriteTimeout:      srv.WriteTimeout,
+			IdleTimeout:       srv.IdleTimeout,
+			MaxHeaderBytes:    srv.MaxHeaderBytes,
+			ShutdownTimeout:   srv.ShutdownTimeout,
+		},
+		Migrations: Migrations{
+			BackupsDir:  db.DefaultBackupsDir,
+			KeepBackups: db.DefaultKeepBackups,
+		},
+		Purge: Purge{
+			Retention: 30 * 24 * time.Hour,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: logging.FormatText,
+		},
+		Metrics: Metrics{
+			Enabled: true,
+		},
+	}
+}
+
+// SQLiteOptions are the connection settings of DB, for PostgreSQL only the
+// pool limits apply
+func (c DB) SQLiteOptions() db.SQLiteOptions {
+	return db.SQLiteOptions{
+		PoolOptions: db.PoolOptions{MaxOpenConns: c.MaxOpenConns, ConnMaxLifetime: c.ConnMaxLifetime},
+		BusyTimeout: c.BusyTimeout,
+	}
+}
+
+// Options are the limits of the HTTP server
+func (c Server) Options() server.Options {
+	return server.Options{
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+		ShutdownTimeout:   c.ShutdownTimeout,
+	}
+}
+
+// Addr is where the server listens
+func (c Server) Addr() string {
+	return net.JoinHostPort("", strconv.Itoa(c.Port))
+}
+
+// Addr is where the metrics server listens, empty when /metrics is served
+// on the API port
+func (c Metrics) Addr() string {
+	if c.Port == 0 {
+		return ""
+	}
+	return net.JoinHostPort("", strconv.Itoa(c.Port))
+}
+
+// Options are the settings of the logger
+func (c Log) Options() logging.Options {
+	return logging.Options{Level: c.Level, Format: c.Format}
+}
+
+// SettingError ties a problem to the setting that has it
+type SettingError struct {
+	Key string
+	Err error
+}
+
+func (e *SettingError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *SettingError) Unwrap() error {
+	return e.Err
+}
+
+// Validate reports every setting that cannot work
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, &SettingError{Key: key, Err: fmt.Errorf(format, args...)})
+		}
+	}
+
+	check(c.DB.Driver == db.DialectSQLite || c.DB.Driver == db.DialectPostgres,
+		"db.driver", "unsupported database driver: %s, use %s or %s", c.DB.Driver, db.DialectSQLite, db.DialectPostgres)
+	check(c.DB.Driver != db.DialectSQLite || c.DB.Path != "", "db.path", "is required with the %s driver", db.DialectSQLite)
+	check(c.DB.Driver != db.DialectPostgres || c.DB.DSN != "", "db.dsn", "is required with the %s driver", db.DialectPostgres)
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns", "cannot be negative")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime", "cannot be negative")
+	check(c.DB.BusyTimeout >= 0, "db.busy_timeout", "cannot be negative")
+
+	check(c.Server.Port >= 0 && c.Server.Port <= 65535, "server.port", "must be between 0 and 65535")
+	if err := c.Server.Options().Validate(); err != nil {
+		errs = append(errs, &SettingError{Key: "server", Err: err})
+	}
+
+	check(c.Migrations.KeepBackups >= 0, "migrations.keep_backups", "cannot be negative")
+	check(c.Purge.Retention > 0, "purge.retention", "must be positive")
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, &SettingError{Key: "log.level", Err: err})
+	}
+	check(c.Log.Format == logging.FormatText || c.Log.Format == logging.FormatJSON,
+		"log.format", "unknown log format: %s, use %s or %s", c.Log.Format, logging.FormatText, logging.FormatJSON)
+
+	check(c.Metrics.Port >= 0 && c.Metrics.Port <= 65535, "metrics.port", "must be between 0 and 65535")
+	check(c.Metrics.Port == 0 || c.Metrics.Port != c.Server.Port, "metrics.port", "must differ from server.port")
+
+	return errors.Join(errs...)
+}
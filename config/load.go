@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// EnvPrefix starts the names of the environment variables, APP_DB_DSN sets
+// db.dsn
+const EnvPrefix = "APP_"
+
+// legacyEnv are the variables the commands read before there was a config
+// package. They still work, the APP_* ones take precedence.
+var legacyEnv = map[string]string{
+	"db.dsn":             "DATABASE_URL",
+	"server.admin_token": "ADMIN_TOKEN",
+}
+
+// setting is a leaf of Config
+type setting struct {
+	key    string
+	secret string
+	value  reflect.Value
+}
+
+// envName is the environment variable of the setting
+func (s setting) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// settings lists the leaves of c in declaration order
+func (c *Config) settings() []setting {
+	var result []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			key := prefix + field.Tag.Get("config")
+			if field.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), key+".")
+				continue
+			}
+			result = append(result, setting{key: key, secret: field.Tag.Get("secret"), value: v.Field(i)})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return result
+}
+
+func (c *Config) setting(key string) (setting, bool) {
+	for _, s := range c.settings() {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// set parses raw into the setting
+func (s setting) set(raw string) error {
+	switch {
+	case s.value.Type() == durationType:
+		d, err := ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(d))
+	case s.value.Kind() == reflect.String:
+		s.value.SetString(raw)
+	case s.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer: %s", raw)
+		}
+		s.value.SetInt(int64(n))
+	case s.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean: %s", raw)
+		}
+		s.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+// format is the inverse of set
+func (s setting) format() any {
+	if s.value.Type() == durationType {
+		return time.Duration(s.value.Int()).String()
+	}
+	return s.value.Interface()
+}
+
+// ParseDuration accepts time.ParseDuration values plus whole days ("30d"),
+// which is how retention windows are usually given
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d, nil
+}
+
+// LoadFile overrides c with the settings of a YAML (.yaml, .yml) or TOML
+// (.toml) file. Settings the file leaves out keep their values, unknown
+// ones are an error so that typos do not go unnoticed.
+func (c *Config) LoadFile(path string) error {
+	var unmarshal func([]byte, any) error
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	case ".toml":
+		unmarshal = toml.Unmarshal
+	default:
+		return fmt.Errorf("unsupported config file format %q, use .yaml, .yml or .toml", ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var tree map[string]any
+	if err := unmarshal(data, &tree); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	var errs []error
+	flatten(tree, "", func(key string, value any) {
+		s, ok := c.setting(key)
+		if !ok {
+			errs = append(errs, &SettingError{Key: key, Err: errors.New("unknown setting")})
+			return
+		}
+		raw := ""
+		if value != nil {
+			raw = fmt.Sprint(value)
+		}
+		if err := s.set(raw); err != nil {
+			errs = append(errs, &SettingError{Key: key, Err: err})
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config file %s: %w", path, errors.Join(errs...))
+	}
+	return nil
+}
+
+// flatten calls fn for every leaf of tree with its dotted key
+func flatten(tree map[string]any, prefix string, fn func(key string, value any)) {
+	for key, value := range tree {
+		if sub, ok := value.(map[string]any); ok {
+			flatten(sub, prefix+key+".", fn)
+			continue
+		}
+		fn(prefix+key, value)
+	}
+}
+
+// LoadEnv overrides c with the APP_* variables lookup finds, os.LookupEnv
+// outside of tests
+func (c *Config) LoadEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	for _, s := range c.settings() {
+		for _, name := range []string{legacyEnv[s.key], s.envName()} {
+			if name == "" {
+				continue
+			}
+			raw, ok := lookup(name)
+			if !ok {
+				continue
+			}
+			if err := s.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("$%s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Redacted replaces secrets
+const Redacted = "REDACTED"
+
+// dsnPassword matches the password of a key=value connection string
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redact hides the secret part of value, all of it unless the setting
+// only keeps its password secret
+func redact(value, secret string) string {
+	if value == "" || secret == "" {
+		return value
+	}
+	if secret != "password" {
+		return Redacted
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), Redacted)
+		}
+		q := u.Query()
+		if q.Has("password") {
+			q.Set("password", Redacted)
+			u.RawQuery = q.Encode()
+		}
+		return strings.Replace(u.String(), url.QueryEscape(Redacted), Redacted, 1)
+	}
+	return dsnPassword.ReplaceAllString(value, "${1}"+Redacted)
+}
+
+// Show writes c as a YAML config file with the secrets redacted. Without
+// secrets it loads back as the same configuration.
+func (c Config) Show(w io.Writer) error {
+	var root yaml.MapSlice
+	sections := map[string]int{}
+	for _, s := range c.settings() {
+		section, key, _ := strings.Cut(s.key, ".")
+		i, ok := sections[section]
+		if !ok {
+			i = len(root)
+			sections[section] = i
+			root = append(root, yaml.MapItem{Key: section, Value: yaml.MapSlice{}})
+		}
+
+		value := s.format()
+		if str, ok := value.(string); ok {
+			value = redact(str, s.secret)
+		}
+		root[i].Value = append(root[i].Value.(yaml.MapSlice), yaml.MapItem{Key: key, Value: value})
+	}
+
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
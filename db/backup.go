@@ -0,0 +1,231 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// DefaultBackupsDir is next to the database file unless it is absolute
+	DefaultBackupsDir = "backups"
+	// DefaultKeepBackups is how many of the newest backups are kept
+	DefaultKeepBackups = 5
+)
+
+var errBackupUnsupported = errors.New("backups are only supported for SQLite, use pg_dump for PostgreSQL")
+
+// backupTimeFormat sorts in time order as a string
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// backupsDir is where the backups of opts.DBFile are stored
+func (opts *MigrationOptions) backupsDir() string {
+	dir := opts.BackupsDir
+	if dir == "" {
+		dir = DefaultBackupsDir
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(opts.DBFile), dir)
+}
+
+// backupPrefix starts the name of every backup of opts.DBFile
+func (opts *MigrationOptions) backupPrefix() string {
+	return strings.TrimSuffix(filepath.Base(opts.DBFile), filepath.Ext(opts.DBFile)) + "-"
+}
+
+// MigrateBackup copies the database into a timestamped file in the backups
+// directory and removes the backups beyond opts.KeepBackups. There is nothing
+// to back up when the database does not exist, the path is empty then.
+func MigrateBackup(opts *MigrationOptions) (string, error) {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
+	}
+	if opts.Dialect != DialectSQLite {
+		return "", errBackupUnsupported
+	}
+
+	return backupDatabase(opts, "")
+}
+
+// backupDatabase is MigrateBackup that never prunes the backup at keep
+func backupDatabase(opts *MigrationOptions, keep string) (string, error) {
+	if _, err := os.Stat(opts.DBFile); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	dir := opts.backupsDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backups dir: %w", err)
+	}
+	name := opts.backupPrefix() + time.Now().UTC().Format(backupTimeFormat) + filepath.Ext(opts.DBFile)
+	path := filepath.Join(dir, name)
+
+	if err := copyDatabase(path, opts.DBFile); err != nil {
+		return "", fmt.Errorf("failed to back up DB: %w", err)
+	}
+	return path, pruneBackups(opts, keep)
+}
+
+// MigrateRestore replaces the database with the contents of a backup, given
+// as a path or as a name in the backups directory. The database is backed up
+// first, so a restore can be undone too.
+func MigrateRestore(opts *MigrationOptions, backup string) error {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
+	}
+	if opts.Dialect != DialectSQLite {
+		return errBackupUnsupported
+	}
+
+	if _, err := os.Stat(backup); os.IsNotExist(err) && !strings.ContainsRune(backup, filepath.Separator) {
+		backup = filepath.Join(opts.backupsDir(), backup)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("backup does not exist: %s", backup)
+	}
+	if opts.DryRun {
+		fmt.Fprintf(opts.output(), "-- would restore %s from %s\n", opts.DBFile, backup)
+		return nil
+	}
+
+	if err := backupBeforeChange(opts, backup); err != nil {
+		return err
+	}
+	if err := copyDatabase(opts.DBFile, backup); err != nil {
+		return fmt.Errorf("failed to restore DB: %w", err)
+	}
+	return nil
+}
+
+// backupBeforeChange backs the database up ahead of a command that can lose
+// data, unless disabled or dry-running. The backup at keep is about to be
+// restored and survives the pruning. PostgreSQL is left to its own backups.
+func backupBeforeChange(opts *MigrationOptions, keep string) error {
+	if opts.NoBackup || opts.DryRun || opts.Dialect != DialectSQLite {
+		return nil
+	}
+
+	path, err := backupDatabase(opts, keep)
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		fmt.Fprintln(opts.output(), "Backup saved to", path)
+	}
+	return nil
+}
+
+// pruneBackups removes the oldest backups of the database so that
+// opts.KeepBackups are left besides keep, zero or less keeps all of them
+func pruneBackups(opts *MigrationOptions, keep string) error {
+	if opts.KeepBackups <= 0 {
+		return nil
+	}
+
+	backups, err := listBackups(opts)
+	if err != nil {
+		return err
+	}
+	backups = slices.DeleteFunc(backups, func(path string) bool {
+		return keep != "" && sameFile(path, keep)
+	})
+	for len(backups) > opts.KeepBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// listBackups lists the backups of opts.DBFile oldest first. A name has to be
+// the prefix, a timestamp and the extension: a prefix match alone would take
+// in the backups of app-old.db as well as those of app.db.
+func listBackups(opts *MigrationOptions) ([]string, error) {
+	entries, err := os.ReadDir(opts.backupsDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(entry.Name(), opts.backupPrefix())
+		if !ok {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, filepath.Ext(opts.DBFile))
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(opts.backupsDir(), entry.Name()))
+	}
+	// ReadDir sorts by name, which is time order
+	return backups, nil
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// copyDatabase copies src into dst with the SQLite online backup API, which
+// gives a consistent copy even while other connections write to src
+func copyDatabase(dst, src string) error {
+	ctx := context.Background()
+
+	srcDB, err := sql.Open(DriverName(DialectSQLite), sqliteURI(src))
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	dstDB, err := sql.Open(DriverName(DialectSQLite), sqliteURI(dst))
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+
+	srcConn, err := srcDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	dstConn, err := dstDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	return dstConn.Raw(func(dstDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			dstSQLite, ok := dstDriver.(*sqlite3.SQLiteConn)
+			srcSQLite, ok2 := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return errors.New("backups need the sqlite3 driver")
+			}
+
+			backup, err := dstSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
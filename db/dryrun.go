@@ -0,0 +1,138 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// migrationStep is one migration a command would run and its direction
+type migrationStep struct {
+	migration *goose.Source
+	up        bool
+}
+
+// migrationPlan picks the steps of a command from all known migrations given
+// the current schema version, the same way goose does when it runs them
+type migrationPlan func(current int64, all []*goose.Source) []migrationStep
+
+func planUpTo(target int64) migrationPlan {
+	return func(current int64, all []*goose.Source) []migrationStep {
+		var steps []migrationStep
+		for _, m := range all {
+			if m.Version > current && m.Version <= target {
+				steps = append(steps, migrationStep{migration: m, up: true})
+			}
+		}
+		return steps
+	}
+}
+
+func planDownTo(target int64) migrationPlan {
+	return func(current int64, all []*goose.Source) []migrationStep {
+		var steps []migrationStep
+		for _, m := range slices.Backward(all) {
+			if m.Version <= current && m.Version > target {
+				steps = append(steps, migrationStep{migration: m})
+			}
+		}
+		return steps
+	}
+}
+
+func planDown(current int64, all []*goose.Source) []migrationStep {
+	steps := planDownTo(0)(current, all)
+	if len(steps) > 1 {
+		steps = steps[:1]
+	}
+	return steps
+}
+
+func planRedo(current int64, all []*goose.Source) []migrationStep {
+	steps := planDown(current, all)
+	if len(steps) == 1 {
+		steps = append(steps, migrationStep{migration: steps[0].migration, up: true})
+	}
+	return steps
+}
+
+// planReset migrates a new database, whatever the current one is at
+func planReset(current int64, all []*goose.Source) []migrationStep {
+	return planUpTo(goose.MaxVersion)(0, all)
+}
+
+// printPlan prints the SQL of the steps plan selects. Go migrations only
+// get a comment, their statements are not known until they run.
+func printPlan(opts *MigrationOptions, plan migrationPlan) error {
+	out := opts.output()
+
+	current, err := currentVersion(opts)
+	if err != nil {
+		return err
+	}
+	all, err := migrationSources(opts)
+	if err != nil {
+		return err
+	}
+
+	steps := plan(current, all)
+	if len(steps) == 0 {
+		fmt.Fprintf(out, "-- no migrations to run, current version: %d\n", current)
+		return nil
+	}
+
+	for _, step := range steps {
+		direction := "Down"
+		if step.up {
+			direction = "Up"
+		}
+		fmt.Fprintf(out, "-- %s %s\n", direction, filepath.Base(step.migration.Path))
+
+		if step.migration.Type == goose.TypeGo {
+			fmt.Fprint(out, "-- Go migration, its statements are known only when it runs\n\n")
+			continue
+		}
+		statements, err := migrationSQL(opts, step.migration.Path, step.up)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s\n\n", statements)
+	}
+	return nil
+}
+
+// migrationSQL returns the Up or Down section of a SQL migration without
+// the goose annotations
+func migrationSQL(opts *MigrationOptions, source string, up bool) (string, error) {
+	fsys, err := opts.migrationsFS()
+	if err != nil {
+		return "", err
+	}
+	content, err := fs.ReadFile(fsys, source)
+	if err != nil {
+		return "", fmt.Errorf("failed to read migration: %w", err)
+	}
+
+	var section strings.Builder
+	inSection := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch annotation := strings.TrimSpace(line); {
+		case strings.HasPrefix(annotation, "-- +goose Up"):
+			inSection = up
+		case strings.HasPrefix(annotation, "-- +goose Down"):
+			inSection = !up
+		case strings.HasPrefix(annotation, "-- +goose"):
+		case inSection:
+			section.WriteString(line + "\n")
+		}
+	}
+	return strings.TrimSpace(section.String()), scanner.Err()
+}
@@ -0,0 +1,107 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: article_revisions.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createRevision = `-- name: CreateRevision :exec
+INSERT INTO article_revisions (article_id, rev, name, body, slug, status, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+`
+
+type CreateRevisionParams struct {
+	ArticleID int64     `json:"article_id"`
+	Rev       int64     `json:"rev"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	Slug      *string   `json:"slug"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateRevision(ctx context.Context, arg CreateRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createRevision,
+		arg.ArticleID,
+		arg.Rev,
+		arg.Name,
+		arg.Body,
+		arg.Slug,
+		arg.Status,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const getRevision = `-- name: GetRevision :one
+SELECT article_id, rev, name, body, slug, status, created_at FROM article_revisions WHERE article_id = ?1 AND rev = ?2
+`
+
+type GetRevisionParams struct {
+	ArticleID int64 `json:"article_id"`
+	Rev       int64 `json:"rev"`
+}
+
+func (q *Queries) GetRevision(ctx context.Context, arg GetRevisionParams) (ArticleRevision, error) {
+	row := q.db.QueryRowContext(ctx, getRevision, arg.ArticleID, arg.Rev)
+	var i ArticleRevision
+	err := row.Scan(
+		&i.ArticleID,
+		&i.Rev,
+		&i.Name,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listRevisions = `-- name: ListRevisions :many
+SELECT article_id, rev, name, body, slug, status, created_at FROM article_revisions
+WHERE article_id = ?1
+ORDER BY rev DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListRevisionsParams struct {
+	ArticleID int64 `json:"article_id"`
+	Offset    int64 `json:"offset"`
+	Limit     int64 `json:"limit"`
+}
+
+func (q *Queries) ListRevisions(ctx context.Context, arg ListRevisionsParams) ([]ArticleRevision, error) {
+	rows, err := q.db.QueryContext(ctx, listRevisions, arg.ArticleID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ArticleRevision
+	for rows.Next() {
+		var i ArticleRevision
+		if err := rows.Scan(
+			&i.ArticleID,
+			&i.Rev,
+			&i.Name,
+			&i.Body,
+			&i.Slug,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,41 +7,144 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createArticle = `-- name: CreateArticle :one
-INSERT INTO articles (name) VALUES (?1) RETURNING id, name
+INSERT INTO articles (name, name_key, body, slug, status, created_at, updated_at, published_at)
+VALUES (
+    ?1, ?2, ?3, ?4, ?5, ?6, ?6,
+    CASE WHEN ?5 = 'published' THEN ?6 END
+)
+RETURNING id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at
 `
 
-func (q *Queries) CreateArticle(ctx context.Context, name string) (Article, error) {
-	row := q.db.QueryRowContext(ctx, createArticle, name)
+type CreateArticleParams struct {
+	Name    string    `json:"name"`
+	NameKey string    `json:"-"`
+	Body    string    `json:"body"`
+	Slug    *string   `json:"slug"`
+	Status  string    `json:"status"`
+	Now     time.Time `json:"now"`
+}
+
+func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (Article, error) {
+	row := q.db.QueryRowContext(ctx, createArticle,
+		arg.Name,
+		arg.NameKey,
+		arg.Body,
+		arg.Slug,
+		arg.Status,
+		arg.Now,
+	)
 	var i Article
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
 	return i, err
 }
 
-const deleteArticle = `-- name: DeleteArticle :exec
-DELETE FROM articles WHERE id = ?1
+const deleteArticle = `-- name: DeleteArticle :execrows
+DELETE FROM articles
+WHERE id = ?1 AND version = coalesce(?2, version)
 `
 
-func (q *Queries) DeleteArticle(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteArticle, id)
-	return err
+type DeleteArticleParams struct {
+	ID      int64         `json:"id"`
+	Version sql.NullInt64 `json:"version"`
+}
+
+// Deletes an article for good, whether it is in the trash or not
+func (q *Queries) DeleteArticle(ctx context.Context, arg DeleteArticleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteArticle, arg.ID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getArticle = `-- name: GetArticle :one
-SELECT id, name FROM articles WHERE id = ?
+SELECT id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at FROM articles WHERE id = ? AND deleted_at IS NULL
 `
 
 func (q *Queries) GetArticle(ctx context.Context, id int64) (Article, error) {
 	row := q.db.QueryRowContext(ctx, getArticle, id)
 	var i Article
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getArticleBySlug = `-- name: GetArticleBySlug :one
+SELECT id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at FROM articles WHERE slug = CAST(?1 AS TEXT) AND deleted_at IS NULL
+`
+
+func (q *Queries) GetArticleBySlug(ctx context.Context, slug string) (Article, error) {
+	row := q.db.QueryRowContext(ctx, getArticleBySlug, slug)
+	var i Article
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getArticleWithDeleted = `-- name: GetArticleWithDeleted :one
+SELECT id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at FROM articles WHERE id = ?
+`
+
+func (q *Queries) GetArticleWithDeleted(ctx context.Context, id int64) (Article, error) {
+	row := q.db.QueryRowContext(ctx, getArticleWithDeleted, id)
+	var i Article
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
 	return i, err
 }
 
 const listArticles = `-- name: ListArticles :many
-SELECT id, name FROM articles ORDER BY id
+SELECT id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at FROM articles WHERE deleted_at IS NULL ORDER BY id
 `
 
 func (q *Queries) ListArticles(ctx context.Context) ([]Article, error) {
@@ -53,7 +156,63 @@ func (q *Queries) ListArticles(ctx context.Context) ([]Article, error) {
 	var items []Article
 	for rows.Next() {
 		var i Article
-		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.NameKey,
+			&i.Body,
+			&i.Slug,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PublishedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesAfter = `-- name: ListArticlesAfter :many
+SELECT id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at FROM articles WHERE id > ?1 AND deleted_at IS NULL ORDER BY id LIMIT ?2
+`
+
+type ListArticlesAfterParams struct {
+	AfterID int64 `json:"after_id"`
+	Limit   int64 `json:"limit"`
+}
+
+func (q *Queries) ListArticlesAfter(ctx context.Context, arg ListArticlesAfterParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listArticlesAfter, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Article
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.NameKey,
+			&i.Body,
+			&i.Slug,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PublishedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -67,18 +226,275 @@ func (q *Queries) ListArticles(ctx context.Context) ([]Article, error) {
 	return items, nil
 }
 
+const listArticlesPage = `-- name: ListArticlesPage :many
+SELECT id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at FROM articles WHERE deleted_at IS NULL ORDER BY id LIMIT ?2 OFFSET ?1
+`
+
+type ListArticlesPageParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+func (q *Queries) ListArticlesPage(ctx context.Context, arg ListArticlesPageParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listArticlesPage, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Article
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.NameKey,
+			&i.Body,
+			&i.Slug,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PublishedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedArticles = `-- name: ListDeletedArticles :many
+SELECT id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at FROM articles WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListDeletedArticlesParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+// The trash, most recently deleted first
+func (q *Queries) ListDeletedArticles(ctx context.Context, arg ListDeletedArticlesParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedArticles, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Article
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.NameKey,
+			&i.Body,
+			&i.Slug,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PublishedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const patchArticle = `-- name: PatchArticle :one
+UPDATE articles SET
+    name = coalesce(?1, name),
+    name_key = coalesce(?2, name_key),
+    body = coalesce(?3, body),
+    slug = coalesce(?4, slug),
+    status = coalesce(?5, status),
+    updated_at = ?6,
+    published_at = CASE
+        WHEN ?5 = 'published' AND published_at IS NULL THEN ?6
+        WHEN ?5 = 'draft' THEN NULL
+        ELSE published_at
+    END,
+    version = version + 1
+WHERE id = ?7 AND version = ?8
+RETURNING id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at
+`
+
+type PatchArticleParams struct {
+	Name    sql.NullString `json:"name"`
+	NameKey sql.NullString `json:"-"`
+	Body    sql.NullString `json:"body"`
+	Slug    *string        `json:"slug"`
+	Status  sql.NullString `json:"status"`
+	Now     time.Time      `json:"now"`
+	ID      int64          `json:"id"`
+	Version int64          `json:"version"`
+}
+
+// Only the columns passed as non-NULL are written, the rest keep their values.
+// Like UpdateArticle it only matches the expected version.
+func (q *Queries) PatchArticle(ctx context.Context, arg PatchArticleParams) (Article, error) {
+	row := q.db.QueryRowContext(ctx, patchArticle,
+		arg.Name,
+		arg.NameKey,
+		arg.Body,
+		arg.Slug,
+		arg.Status,
+		arg.Now,
+		arg.ID,
+		arg.Version,
+	)
+	var i Article
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const purgeDeletedArticles = `-- name: PurgeDeletedArticles :execrows
+DELETE FROM articles WHERE deleted_at IS NOT NULL AND deleted_at < ?1
+`
+
+func (q *Queries) PurgeDeletedArticles(ctx context.Context, before *time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedArticles, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreArticle = `-- name: RestoreArticle :one
+UPDATE articles SET
+    deleted_at = NULL,
+    version = version + 1
+WHERE id = ?1 AND deleted_at IS NOT NULL
+RETURNING id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at
+`
+
+func (q *Queries) RestoreArticle(ctx context.Context, id int64) (Article, error) {
+	row := q.db.QueryRowContext(ctx, restoreArticle, id)
+	var i Article
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const softDeleteArticle = `-- name: SoftDeleteArticle :execrows
+UPDATE articles SET
+    deleted_at = ?1,
+    version = version + 1
+WHERE id = ?2 AND deleted_at IS NULL
+    AND version = coalesce(?3, version)
+`
+
+type SoftDeleteArticleParams struct {
+	Now     *time.Time    `json:"now"`
+	ID      int64         `json:"id"`
+	Version sql.NullInt64 `json:"version"`
+}
+
+// Moves an article to the trash. The version check is skipped when no
+// version is given.
+func (q *Queries) SoftDeleteArticle(ctx context.Context, arg SoftDeleteArticleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteArticle, arg.Now, arg.ID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateArticle = `-- name: UpdateArticle :one
-UPDATE articles SET name = ?1 WHERE id = ?2 RETURNING id, name
+UPDATE articles SET
+    name = ?1,
+    name_key = ?2,
+    body = ?3,
+    slug = ?4,
+    status = ?5,
+    updated_at = ?6,
+    published_at = CASE
+        WHEN ?5 = 'published' AND published_at IS NULL THEN ?6
+        WHEN ?5 = 'draft' THEN NULL
+        ELSE published_at
+    END,
+    version = version + 1
+WHERE id = ?7 AND version = ?8
+RETURNING id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at
 `
 
 type UpdateArticleParams struct {
-	Name string `json:"name"`
-	ID   int64  `json:"id"`
+	Name    string    `json:"name"`
+	NameKey string    `json:"-"`
+	Body    string    `json:"body"`
+	Slug    *string   `json:"slug"`
+	Status  string    `json:"status"`
+	Now     time.Time `json:"now"`
+	ID      int64     `json:"id"`
+	Version int64     `json:"version"`
 }
 
+// published_at is set the first time an article becomes published and is
+// cleared when it goes back to draft; archiving keeps it. No row is returned
+// when the article is not at the expected version anymore.
 func (q *Queries) UpdateArticle(ctx context.Context, arg UpdateArticleParams) (Article, error) {
-	row := q.db.QueryRowContext(ctx, updateArticle, arg.Name, arg.ID)
+	row := q.db.QueryRowContext(ctx, updateArticle,
+		arg.Name,
+		arg.NameKey,
+		arg.Body,
+		arg.Slug,
+		arg.Status,
+		arg.Now,
+		arg.ID,
+		arg.Version,
+	)
 	var i Article
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
+		&i.Body,
+		&i.Slug,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublishedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
 	return i, err
 }
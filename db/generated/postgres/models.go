@@ -0,0 +1,39 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+
+package pgdb
+
+import (
+	"time"
+)
+
+type Article struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	NameKey     string     `json:"-"`
+	Body        string     `json:"body"`
+	Slug        *string    `json:"slug"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	PublishedAt *time.Time `json:"published_at"`
+	Version     int64      `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+}
+
+type ArticleRevision struct {
+	ArticleID int64     `json:"article_id"`
+	Rev       int64     `json:"rev"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	Slug      *string   `json:"slug"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArticleSlug struct {
+	Slug      string    `json:"slug"`
+	ArticleID int64     `json:"article_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
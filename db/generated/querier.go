@@ -0,0 +1,48 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type Querier interface {
+	AddSlugHistory(ctx context.Context, arg AddSlugHistoryParams) error
+	CreateArticle(ctx context.Context, arg CreateArticleParams) (Article, error)
+	CreateRevision(ctx context.Context, arg CreateRevisionParams) error
+	// Deletes an article for good, whether it is in the trash or not
+	DeleteArticle(ctx context.Context, arg DeleteArticleParams) (int64, error)
+	DeleteSlugHistory(ctx context.Context, slug string) error
+	GetArticle(ctx context.Context, id int64) (Article, error)
+	GetArticleBySlug(ctx context.Context, slug string) (Article, error)
+	GetArticleWithDeleted(ctx context.Context, id int64) (Article, error)
+	// Resolves a former slug to the slug its article has now
+	GetCurrentSlug(ctx context.Context, slug string) (*string, error)
+	GetRevision(ctx context.Context, arg GetRevisionParams) (ArticleRevision, error)
+	ListArticles(ctx context.Context) ([]Article, error)
+	ListArticlesAfter(ctx context.Context, arg ListArticlesAfterParams) ([]Article, error)
+	ListArticlesPage(ctx context.Context, arg ListArticlesPageParams) ([]Article, error)
+	// The trash, most recently deleted first
+	ListDeletedArticles(ctx context.Context, arg ListDeletedArticlesParams) ([]Article, error)
+	ListRevisions(ctx context.Context, arg ListRevisionsParams) ([]ArticleRevision, error)
+	// Slugs equal to the base or derived from it with a suffix that belong to
+	// other articles, either currently or in their history
+	ListTakenSlugs(ctx context.Context, arg ListTakenSlugsParams) ([]string, error)
+	// Only the columns passed as non-NULL are written, the rest keep their values.
+	// Like UpdateArticle it only matches the expected version.
+	PatchArticle(ctx context.Context, arg PatchArticleParams) (Article, error)
+	PurgeDeletedArticles(ctx context.Context, before *time.Time) (int64, error)
+	RestoreArticle(ctx context.Context, id int64) (Article, error)
+	// Moves an article to the trash. The version check is skipped when no
+	// version is given.
+	SoftDeleteArticle(ctx context.Context, arg SoftDeleteArticleParams) (int64, error)
+	// published_at is set the first time an article becomes published and is
+	// cleared when it goes back to draft; archiving keeps it. No row is returned
+	// when the article is not at the expected version anymore.
+	UpdateArticle(ctx context.Context, arg UpdateArticleParams) (Article, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -1,50 +1,194 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/hexlet-components/go-gin-example/db/migrations"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pressly/goose/v3"
 )
 
 const (
-	DefaultDBFile        = "app.db"
+	DefaultDBFile = "app.db"
+	// DefaultMigrationsDir is where the migrations live in the source tree,
+	// new ones are created there
 	DefaultMigrationsDir = "db/migrations"
 )
 
+// The dialects the migrations are written for, they double as goose dialects
+const (
+	DialectSQLite   = "sqlite3"
+	DialectPostgres = "postgres"
+)
+
 // MigrationOptions contains configuration for database migrations
 type MigrationOptions struct {
-	DBFile        string
+	DBFile string
+	// DSN is the connection string of a PostgreSQL database, DBFile is only
+	// used with SQLite
+	DSN string
+	// FS is where MigrationsDir is looked up, nil means the OS filesystem
+	FS            fs.FS
 	MigrationsDir string
 	Dialect       string
+	// DryRun prints the SQL that would run to Out instead of running it
+	DryRun bool
+	// Out is where migrations, dry runs and backups report, nil means
+	// os.Stdout
+	Out io.Writer
+	// BackupsDir is relative to the directory of DBFile, empty means
+	// DefaultBackupsDir. Commands that can lose data back the database up
+	// there first unless NoBackup is set. Only SQLite databases are backed up.
+	BackupsDir  string
+	KeepBackups int
+	NoBackup    bool
 }
 
-// DefaultMigrationOptions returns default migration options
+// DefaultMigrationOptions returns default migration options, the migrations
+// are the ones embedded into the binary
 func DefaultMigrationOptions() *MigrationOptions {
 	return &MigrationOptions{
 		DBFile:        DefaultDBFile,
-		MigrationsDir: DefaultMigrationsDir,
-		Dialect:       "sqlite3",
+		FS:            migrations.FS,
+		MigrationsDir: ".",
+		Dialect:       DialectSQLite,
+		KeepBackups:   DefaultKeepBackups,
+	}
+}
+
+// PostgresMigrationOptions returns options that migrate the PostgreSQL
+// database at dsn with the embedded PostgreSQL migrations
+func PostgresMigrationOptions(dsn string) *MigrationOptions {
+	return &MigrationOptions{
+		DSN:           dsn,
+		FS:            migrations.FS,
+		MigrationsDir: migrations.PostgresDir,
+		Dialect:       DialectPostgres,
+	}
+}
+
+// DriverName returns the database/sql driver that opens a database of
+// dialect
+func DriverName(dialect string) string {
+	if dialect == DialectPostgres {
+		return "pgx"
 	}
+	return dialect
+}
+
+func (opts *MigrationOptions) output() io.Writer {
+	if opts.Out == nil {
+		return os.Stdout
+	}
+	return opts.Out
+}
+
+// dataSource is what the driver of opts.Dialect is opened with
+func (opts *MigrationOptions) dataSource() string {
+	if opts.Dialect == DialectPostgres {
+		return opts.DSN
+	}
+	return sqliteURI(opts.DBFile)
+}
+
+// MigrationsFromDir makes opts read the migrations from a directory on disk
+// instead of the embedded set
+func (opts *MigrationOptions) MigrationsFromDir(dir string) {
+	opts.FS = nil
+	opts.MigrationsDir = dir
+}
+
+// migrationsFS is the directory with the migrations
+func (opts *MigrationOptions) migrationsFS() (fs.FS, error) {
+	if opts.FS == nil {
+		// os.DirFS of a missing directory is just empty
+		if _, err := os.Stat(opts.MigrationsDir); err != nil {
+			return nil, fmt.Errorf("failed to read migrations dir: %w", err)
+		}
+		return os.DirFS(opts.MigrationsDir), nil
+	}
+	return fs.Sub(opts.FS, opts.MigrationsDir)
 }
 
 // openDB opens a database connection with the given options
 func openDB(opts *MigrationOptions) (*sql.DB, error) {
-	goose.SetDialect(opts.Dialect)
-
-	db, err := sql.Open(opts.Dialect, opts.DBFile)
+	db, err := sql.Open(DriverName(opts.Dialect), opts.dataSource())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open DB: %w", err)
 	}
+	return db, nil
+}
 
-	if _, err := goose.EnsureDBVersion(db); err != nil {
-		// This is not fatal, the table will be created by the first migration
-		fmt.Printf("Warning: failed to ensure goose_db_version table (will be created by first migration): %v\n", err)
+// newProvider collects the migrations of opts. The Go migrations are
+// registered globally by the migrations package and are written for SQLite,
+// other dialects only get the SQL files of their directory.
+func newProvider(opts *MigrationOptions, db *sql.DB) (*goose.Provider, error) {
+	fsys, err := opts.migrationsFS()
+	if err != nil {
+		return nil, err
 	}
+	return goose.NewProvider(goose.Dialect(opts.Dialect), db, fsys,
+		goose.WithDisableGlobalRegistry(opts.Dialect != DialectSQLite))
+}
 
-	return db, nil
+// migrate runs migrations against the database and reports every one it
+// runs, or prints what plan selects in dry-run mode. Destructive commands
+// back the database up first.
+func migrate(opts *MigrationOptions, destructive bool, run func(ctx context.Context, p *goose.Provider) ([]*goose.MigrationResult, error), plan migrationPlan) error {
+	if opts.DryRun {
+		return printPlan(opts, plan)
+	}
+	if destructive {
+		if err := backupBeforeChange(opts, ""); err != nil {
+			return err
+		}
+	}
+
+	db, err := openDB(opts)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	p, err := newProvider(opts, db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if destructive && opts.Dialect == DialectSQLite {
+		if err := migrations.PrepareDown(ctx, db); err != nil {
+			return fmt.Errorf("failed to prepare rollback: %w", err)
+		}
+	}
+	results, err := run(ctx, p)
+	for _, result := range results {
+		fmt.Fprintln(opts.output(), result)
+	}
+	if err == nil && !destructive {
+		err = SyncSearchIndex(ctx, db, opts.Dialect)
+	}
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		return errors.New("no migrations to roll back")
+	}
+	return err
+}
+
+// one adapts the provider methods that run a single migration
+func one(result *goose.MigrationResult, err error) ([]*goose.MigrationResult, error) {
+	if result == nil {
+		return nil, err
+	}
+	return []*goose.MigrationResult{result}, err
 }
 
 // MigrateUp applies all available migrations
@@ -53,13 +197,20 @@ func MigrateUp(opts *MigrationOptions) error {
 		opts = DefaultMigrationOptions()
 	}
 
-	db, err := openDB(opts)
-	if err != nil {
-		return err
+	return migrate(opts, false, func(ctx context.Context, p *goose.Provider) ([]*goose.MigrationResult, error) {
+		return p.Up(ctx)
+	}, planUpTo(goose.MaxVersion))
+}
+
+// MigrateUpTo applies the pending migrations up to and including version
+func MigrateUpTo(opts *MigrationOptions, version int64) error {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
 	}
-	defer db.Close()
 
-	return goose.Up(db, opts.MigrationsDir)
+	return migrate(opts, false, func(ctx context.Context, p *goose.Provider) ([]*goose.MigrationResult, error) {
+		return p.UpTo(ctx, version)
+	}, planUpTo(version))
 }
 
 // MigrateDown rolls back the last migration
@@ -68,13 +219,46 @@ func MigrateDown(opts *MigrationOptions) error {
 		opts = DefaultMigrationOptions()
 	}
 
-	db, err := openDB(opts)
-	if err != nil {
-		return err
+	return migrate(opts, true, func(ctx context.Context, p *goose.Provider) ([]*goose.MigrationResult, error) {
+		return one(p.Down(ctx))
+	}, planDown)
+}
+
+// MigrateDownTo rolls back the migrations above version
+func MigrateDownTo(opts *MigrationOptions, version int64) error {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
 	}
-	defer db.Close()
 
-	return goose.Down(db, opts.MigrationsDir)
+	return migrate(opts, true, func(ctx context.Context, p *goose.Provider) ([]*goose.MigrationResult, error) {
+		return p.DownTo(ctx, version)
+	}, planDownTo(version))
+}
+
+// MigrateRedo rolls back the last migration and applies it again
+func MigrateRedo(opts *MigrationOptions) error {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
+	}
+
+	return migrate(opts, true, func(ctx context.Context, p *goose.Provider) ([]*goose.MigrationResult, error) {
+		down, err := p.Down(ctx)
+		if err != nil {
+			return one(down, err)
+		}
+		up, err := p.ApplyVersion(ctx, down.Source.Version, true)
+		return append([]*goose.MigrationResult{down}, up), err
+	}, planRedo)
+}
+
+// MigrateVersion returns the current schema version, 0 for a database that
+// has not been migrated yet. The database is not created or changed.
+func MigrateVersion(opts *MigrationOptions) (int64, error) {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
+	}
+
+	return currentVersion(opts)
 }
 
 // MigrateStatus shows the current migration status
@@ -89,15 +273,53 @@ func MigrateStatus(opts *MigrationOptions) error {
 	}
 	defer db.Close()
 
-	return goose.Status(db, opts.MigrationsDir)
+	p, err := newProvider(opts, db)
+	if err != nil {
+		return err
+	}
+	statuses, err := p.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	out := opts.output()
+	fmt.Fprintln(out, "    Applied At                  Migration")
+	fmt.Fprintln(out, "    =======================================")
+	for _, status := range statuses {
+		appliedAt := "Pending"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.Format(time.ANSIC)
+		}
+		fmt.Fprintf(out, "    %-24s -- %s\n", appliedAt, filepath.Base(status.Source.Path))
+	}
+	return nil
 }
 
-// MigrateReset removes the database file and applies all migrations from scratch
+// MigrateReset recreates the database and applies all migrations from
+// scratch. A SQLite file is removed, a PostgreSQL database has all its
+// migrations rolled back.
 func MigrateReset(opts *MigrationOptions) error {
 	if opts == nil {
 		opts = DefaultMigrationOptions()
 	}
 
+	if opts.DryRun {
+		return printPlan(opts, planReset)
+	}
+
+	if opts.Dialect != DialectSQLite {
+		if err := migrate(opts, true, func(ctx context.Context, p *goose.Provider) ([]*goose.MigrationResult, error) {
+			return p.DownTo(ctx, 0)
+		}, planReset); err != nil {
+			return err
+		}
+		return MigrateUp(opts)
+	}
+
+	if err := backupBeforeChange(opts, ""); err != nil {
+		return err
+	}
+
 	// Remove the database file
 	if err := os.Remove(opts.DBFile); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove DB file: %w", err)
@@ -107,13 +329,111 @@ func MigrateReset(opts *MigrationOptions) error {
 	return MigrateUp(opts)
 }
 
+// MigrateTo migrates up or down to the given version
+func MigrateTo(opts *MigrationOptions, version int64) error {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
+	}
+
+	current, err := currentVersion(opts)
+	if err != nil {
+		return err
+	}
+	if version < current {
+		return MigrateDownTo(opts, version)
+	}
+	return MigrateUpTo(opts, version)
+}
+
+// MigrateCreate writes a new blank migration, migrationType is "sql" or "go".
+// It is numbered after the last migration in the directory with the same
+// three digits the existing ones use, goose itself would pad to five.
+func MigrateCreate(opts *MigrationOptions, name, migrationType string) (string, error) {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
+	}
+
+	tmpl, ok := migrationTemplates[migrationType]
+	if !ok {
+		return "", fmt.Errorf("unknown migration type: %s", migrationType)
+	}
+
+	// The embedded set is read-only, new migrations go to the source tree
+	dir := opts.MigrationsDir
+	if opts.FS != nil {
+		dir = filepath.Join(DefaultMigrationsDir, opts.MigrationsDir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read migrations dir: %w", err)
+	}
+	var last int64
+	for _, entry := range entries {
+		version, err := goose.NumericComponent(entry.Name())
+		if err == nil && version > last {
+			last = version
+		}
+	}
+
+	snake := migrationNameReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+	path := filepath.Join(dir, fmt.Sprintf("%03d_%s.%s", last+1, snake, migrationType))
+
+	var camel strings.Builder
+	for _, word := range strings.Split(snake, "_") {
+		if word != "" {
+			camel.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	content := strings.ReplaceAll(tmpl, "{{name}}", camel.String())
+
+	// O_EXCL so that an existing migration is never overwritten
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+var migrationNameReplacer = strings.NewReplacer(" ", "_", "-", "_", ".", "_")
+
+var migrationTemplates = map[string]string{
+	"sql": `-- +goose Up
+
+-- +goose Down
+`,
+	"go": `package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(up{{name}}, down{{name}})
+}
+
+func up{{name}}(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+
+func down{{name}}(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+`,
+}
+
 // SetupTestDB creates a test database with all migrations applied
 func SetupTestDB(dbFile string) (*sql.DB, error) {
-	opts := &MigrationOptions{
-		DBFile:        dbFile,
-		MigrationsDir: DefaultMigrationsDir,
-		Dialect:       "sqlite3",
-	}
+	opts := DefaultMigrationOptions()
+	opts.DBFile = dbFile
 
 	// Remove existing test DB if it exists
 	if err := os.Remove(dbFile); err != nil && !os.IsNotExist(err) {
@@ -126,7 +446,7 @@ func SetupTestDB(dbFile string) (*sql.DB, error) {
 	}
 
 	// Open and return the database connection
-	db, err := sql.Open("sqlite3", dbFile)
+	db, err := sql.Open("sqlite3", sqliteURI(dbFile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open test DB: %w", err)
 	}
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateArticlesSearch, downCreateArticlesSearch)
+}
+
+// upCreateArticlesSearch builds an external-content FTS5 index over articles
+// and keeps it in sync with triggers.
+//
+// FTS5 is only compiled into mattn/go-sqlite3 with the sqlite_fts5 build tag,
+// so the index is left to SyncSearchIndex, which builds it whenever a binary
+// that has FTS5 opens the database, not only when this migration runs.
+func upCreateArticlesSearch(ctx context.Context, tx *sql.Tx) error {
+	return syncSearchIndex(ctx, tx)
+}
+
+func downCreateArticlesSearch(ctx context.Context, tx *sql.Tx) error {
+	return dropSearchIndex(ctx, tx)
+}
+
+// createSearchIndex creates articles_fts over the given articles columns. The
+// unicode61 tokenizer folds case for Cyrillic as well as Latin and strips
+// Latin diacritics, but it leaves "ё" alone, so the indexed text has "ё"
+// replaced with "е" (the search handler does the same to the query).
+func createSearchIndex(ctx context.Context, tx *sql.Tx, columns ...string) error {
+	normalized := func(table string) string {
+		values := make([]string, 0, len(columns))
+		for _, col := range columns {
+			values = append(values, fmt.Sprintf("replace(replace(%s.%s, 'ё', 'е'), 'Ё', 'Е')", table, col))
+		}
+		return strings.Join(values, ", ")
+	}
+	list := strings.Join(columns, ", ")
+
+	return execAll(ctx, tx,
+		`CREATE VIRTUAL TABLE articles_fts USING fts5(
+			`+list+`,
+			content = 'articles',
+			content_rowid = 'id',
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER articles_fts_ai AFTER INSERT ON articles BEGIN
+			INSERT INTO articles_fts (rowid, `+list+`) VALUES (new.id, `+normalized("new")+`);
+		END`,
+		`CREATE TRIGGER articles_fts_ad AFTER DELETE ON articles BEGIN
+			INSERT INTO articles_fts (articles_fts, rowid, `+list+`) VALUES ('delete', old.id, `+normalized("old")+`);
+		END`,
+		`CREATE TRIGGER articles_fts_au AFTER UPDATE ON articles BEGIN
+			INSERT INTO articles_fts (articles_fts, rowid, `+list+`) VALUES ('delete', old.id, `+normalized("old")+`);
+			INSERT INTO articles_fts (rowid, `+list+`) VALUES (new.id, `+normalized("new")+`);
+		END`,
+		`INSERT INTO articles_fts (rowid, `+list+`) SELECT id, `+normalized("articles")+` FROM articles`,
+	)
+}
+
+// dropSearchIndex removes articles_fts and its triggers. Without FTS5 the
+// table cannot be dropped, so only the triggers go and the table is left to
+// a binary that has the module.
+func dropSearchIndex(ctx context.Context, tx *sql.Tx) error {
+	if err := dropSearchTriggers(ctx, tx); err != nil {
+		return err
+	}
+	enabled, err := fts5Enabled(ctx, tx)
+	if err != nil || !enabled {
+		return err
+	}
+	return execAll(ctx, tx, `DROP TABLE IF EXISTS articles_fts`)
+}
+
+func dropSearchTriggers(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`DROP TRIGGER IF EXISTS articles_fts_au`,
+		`DROP TRIGGER IF EXISTS articles_fts_ad`,
+		`DROP TRIGGER IF EXISTS articles_fts_ai`,
+	)
+}
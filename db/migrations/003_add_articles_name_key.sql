@@ -0,0 +1,7 @@
+-- +goose Up
+-- name_key holds the case-folded, NFKC-normalized name and backs the unique
+-- index created by the next migration once existing rows are backfilled
+ALTER TABLE articles ADD COLUMN name_key TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE articles DROP COLUMN name_key;
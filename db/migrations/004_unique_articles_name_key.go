@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upUniqueArticlesNameKey, downUniqueArticlesNameKey)
+}
+
+// upUniqueArticlesNameKey backfills name_key, which needs Unicode rules SQLite
+// does not have, and then makes it unique. Existing duplicates are reported
+// rather than renamed, someone has to decide which article keeps the name.
+func upUniqueArticlesNameKey(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM articles`)
+	if err != nil {
+		return err
+	}
+
+	keys := map[int64]string{}
+	owners := map[string][]int64{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		key := textnorm.NameKey(name)
+		keys[id] = key
+		owners[key] = append(owners[key], id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var duplicates []string
+	for key, ids := range owners {
+		if len(ids) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%q (ids %v)", key, ids))
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("articles with duplicate names must be renamed first: %s", strings.Join(duplicates, ", "))
+	}
+
+	for id, key := range keys {
+		if _, err := tx.ExecContext(ctx, `UPDATE articles SET name_key = ? WHERE id = ?`, key, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `CREATE UNIQUE INDEX articles_name_key_idx ON articles (name_key)`)
+	return err
+}
+
+func downUniqueArticlesNameKey(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx, `DROP INDEX IF EXISTS articles_name_key_idx`)
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+-- SQLite cannot add columns with a non-constant default, so the timestamps get
+-- a placeholder that is overwritten right away and the queries always set them.
+-- Timestamps are stored in the format go-sqlite3 writes time.Time values in,
+-- so that stored and bound values compare as equal strings.
+ALTER TABLE articles ADD COLUMN body TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN slug TEXT;
+ALTER TABLE articles ADD COLUMN status TEXT NOT NULL DEFAULT 'draft'
+    CHECK (status IN ('draft', 'published', 'archived'));
+ALTER TABLE articles ADD COLUMN created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE articles ADD COLUMN updated_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE articles ADD COLUMN published_at DATETIME;
+
+-- Articles created before statuses existed were already public
+UPDATE articles SET
+    status = 'published',
+    created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'),
+    updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'),
+    published_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+
+CREATE UNIQUE INDEX articles_slug_idx ON articles (slug);
+CREATE INDEX articles_status_idx ON articles (status);
+
+-- +goose Down
+DROP INDEX IF EXISTS articles_status_idx;
+DROP INDEX IF EXISTS articles_slug_idx;
+ALTER TABLE articles DROP COLUMN published_at;
+ALTER TABLE articles DROP COLUMN updated_at;
+ALTER TABLE articles DROP COLUMN created_at;
+ALTER TABLE articles DROP COLUMN status;
+ALTER TABLE articles DROP COLUMN slug;
+ALTER TABLE articles DROP COLUMN body;
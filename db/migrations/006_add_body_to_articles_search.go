@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddBodyToArticlesSearch, downAddBodyToArticlesSearch)
+}
+
+// upAddBodyToArticlesSearch rebuilds articles_fts to cover the body column.
+// FTS5 tables cannot be altered, so the index is dropped and filled again.
+func upAddBodyToArticlesSearch(ctx context.Context, tx *sql.Tx) error {
+	return syncSearchIndex(ctx, tx)
+}
+
+func downAddBodyToArticlesSearch(ctx context.Context, tx *sql.Tx) error {
+	return rebuildSearchIndex(ctx, tx, "name")
+}
+
+// rebuildSearchIndex recreates articles_fts if an earlier migration created
+// it. Without FTS5 only its triggers can be dropped.
+func rebuildSearchIndex(ctx context.Context, tx *sql.Tx, columns ...string) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'articles_fts'`,
+	).Scan(&exists)
+	if err != nil || !exists {
+		return err
+	}
+
+	if err := dropSearchIndex(ctx, tx); err != nil {
+		return err
+	}
+	enabled, err := fts5Enabled(ctx, tx)
+	if err != nil || !enabled {
+		return err
+	}
+	return createSearchIndex(ctx, tx, columns...)
+}
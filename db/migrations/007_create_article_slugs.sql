@@ -0,0 +1,12 @@
+-- +goose Up
+-- Slugs an article had before, so that old URLs keep redirecting to it
+CREATE TABLE IF NOT EXISTS article_slugs (
+    slug TEXT PRIMARY KEY,
+    article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX article_slugs_article_id_idx ON article_slugs (article_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS article_slugs;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Incremented by every update, it backs the ETag of an article and lets
+-- writers update conditionally on the version they have read
+ALTER TABLE articles ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE articles DROP COLUMN version;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Deleted articles stay in the table until they are purged. They keep their
+-- name and slug reserved, so restoring one can never conflict.
+ALTER TABLE articles ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX articles_deleted_at_idx ON articles (deleted_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS articles_deleted_at_idx;
+ALTER TABLE articles DROP COLUMN deleted_at;
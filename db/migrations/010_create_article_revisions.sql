@@ -0,0 +1,17 @@
+-- +goose Up
+-- Earlier states of an article, saved whenever it is updated. rev is the
+-- version the article had in that state and created_at is when that state
+-- was written.
+CREATE TABLE IF NOT EXISTS article_revisions (
+    article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    rev INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    body TEXT NOT NULL,
+    slug TEXT,
+    status TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (article_id, rev)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS article_revisions;
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upBackfillArticleSlugs, downBackfillArticleSlugs)
+}
+
+// upBackfillArticleSlugs gives a slug to the articles created before slugs
+// existed, generated from the name the same way the API does, with a -2, -3,
+// ... suffix when it is taken by another article now or in the past
+func upBackfillArticleSlugs(ctx context.Context, tx *sql.Tx) error {
+	taken := map[string]bool{}
+	for _, query := range []string{
+		`SELECT slug FROM articles WHERE slug IS NOT NULL`,
+		`SELECT slug FROM article_slugs`,
+	} {
+		slugs, err := queryStrings(ctx, tx, query)
+		if err != nil {
+			return err
+		}
+		for _, slug := range slugs {
+			taken[slug] = true
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM articles WHERE slug IS NULL ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	slugs := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		base := textnorm.Slugify(name)
+		slug := base
+		for n := 2; taken[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[slug] = true
+		slugs[id] = slug
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, slug := range slugs {
+		if _, err := tx.ExecContext(ctx, `UPDATE articles SET slug = ? WHERE id = ?`, slug, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downBackfillArticleSlugs keeps the slugs: they may already be in links and
+// there is no telling them apart from the ones set through the API
+func downBackfillArticleSlugs(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import "embed"
+
+// PostgresDir holds the PostgreSQL version of the schema within FS
+const PostgresDir = "postgres"
+
+// FS holds the SQL migrations. The Go ones are compiled into the binary and
+// registered in init, goose runs every registered Go migration when the FS
+// has no .go files, so FS together with this package is the complete set.
+// They are written for SQLite, PostgreSQL gets the plain SQL files in
+// PostgresDir.
+//
+//go:embed *.sql postgres/*.sql
+var FS embed.FS
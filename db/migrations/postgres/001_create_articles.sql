@@ -0,0 +1,26 @@
+-- +goose Up
+-- The PostgreSQL schema starts from where the SQLite migrations have got to,
+-- so it has no history to replay. Columns are in the same order as in SQLite,
+-- which gives the models sqlc generates for both engines the same shape.
+CREATE TABLE articles (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT NOT NULL,
+    name_key TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    slug TEXT,
+    status TEXT NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'published', 'archived')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at TIMESTAMPTZ,
+    version BIGINT NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX articles_name_key_idx ON articles (name_key);
+CREATE UNIQUE INDEX articles_slug_idx ON articles (slug);
+CREATE INDEX articles_status_idx ON articles (status);
+CREATE INDEX articles_deleted_at_idx ON articles (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS articles;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Earlier states of an article, see the SQLite migration of the same name
+CREATE TABLE article_revisions (
+    article_id BIGINT NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    rev BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    body TEXT NOT NULL,
+    slug TEXT,
+    status TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (article_id, rev)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS article_revisions;
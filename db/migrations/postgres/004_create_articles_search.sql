@@ -0,0 +1,10 @@
+-- +goose Up
+-- Full-text search over the name and the body. The 'simple' configuration
+-- does no stemming, like the unicode61 tokenizer of the SQLite index, and "ё"
+-- is folded to "е" the same way. The search query repeats this expression.
+CREATE INDEX articles_search_idx ON articles USING GIN (
+    to_tsvector('simple', translate(name || ' ' || body, 'ёЁ', 'еЕ'))
+);
+
+-- +goose Down
+DROP INDEX IF EXISTS articles_search_idx;
@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+)
+
+// SearchIndexVersion is the migration that last changed what articles_fts
+// covers
+const SearchIndexVersion = 6
+
+// searchColumns are the articles columns articles_fts covers once the
+// migrations that add them have run
+var searchColumns = []string{"name", "body"}
+
+// SyncSearchIndex brings articles_fts in line with the binary, whichever one
+// ran the migrations. With FTS5 the index is built, or rebuilt when its
+// triggers or columns are missing. Without it the triggers are dropped, as
+// every write to articles would fail on them, and search stays unavailable.
+// Running it again changes nothing.
+func SyncSearchIndex(ctx context.Context, database *sql.DB) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := syncSearchIndex(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PrepareDown readies a database for rolling migrations back. Without FTS5
+// the articles_fts triggers are dropped first: SQLite checks them whenever
+// articles changes and fails on the missing module, leaving the rollback
+// half done. With FTS5 it does nothing.
+func PrepareDown(ctx context.Context, database *sql.DB) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	enabled, err := fts5Enabled(ctx, tx)
+	if err != nil || enabled {
+		return err
+	}
+	if err := dropSearchTriggers(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func syncSearchIndex(ctx context.Context, tx *sql.Tx) error {
+	articles, err := queryStrings(ctx, tx, `SELECT name FROM pragma_table_info('articles')`)
+	if err != nil || len(articles) == 0 {
+		return err
+	}
+	columns := slices.DeleteFunc(slices.Clone(searchColumns), func(col string) bool {
+		return !slices.Contains(articles, col)
+	})
+
+	triggers, err := queryStrings(ctx, tx,
+		`SELECT name FROM sqlite_master WHERE type = 'trigger' AND tbl_name = 'articles' AND name LIKE 'articles\_fts\_%' ESCAPE '\'`)
+	if err != nil {
+		return err
+	}
+
+	enabled, err := fts5Enabled(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		slog.WarnContext(ctx, "FTS5 is not available, search is disabled, build with -tags sqlite_fts5")
+		if len(triggers) == 0 {
+			return nil
+		}
+		// A binary with FTS5 rebuilds the index as the triggers are gone
+		return dropSearchTriggers(ctx, tx)
+	}
+
+	indexed, err := queryStrings(ctx, tx, `SELECT name FROM pragma_table_info('articles_fts')`)
+	if err != nil {
+		return err
+	}
+	if len(triggers) == 3 && slices.Equal(indexed, columns) {
+		return nil
+	}
+
+	slog.InfoContext(ctx, "Building the search index", slog.Any("columns", columns))
+	if err := dropSearchIndex(ctx, tx); err != nil {
+		return err
+	}
+	return createSearchIndex(ctx, tx, columns...)
+}
@@ -0,0 +1,12 @@
+-- name: CreateRevision :exec
+INSERT INTO article_revisions (article_id, rev, name, body, slug, status, created_at)
+VALUES (:article_id, :rev, :name, :body, :slug, :status, :created_at);
+
+-- name: ListRevisions :many
+SELECT * FROM article_revisions
+WHERE article_id = :article_id
+ORDER BY rev DESC
+LIMIT :limit OFFSET :offset;
+
+-- name: GetRevision :one
+SELECT * FROM article_revisions WHERE article_id = :article_id AND rev = :rev;
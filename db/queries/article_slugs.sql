@@ -0,0 +1,24 @@
+-- name: ListTakenSlugs :many
+-- Slugs equal to the base or derived from it with a suffix that belong to
+-- other articles, either currently or in their history
+SELECT CAST(a.slug AS TEXT) AS slug FROM articles a
+WHERE (a.slug = CAST(:base AS TEXT) OR a.slug LIKE :base || '-%') AND a.id <> :article_id
+UNION
+SELECT h.slug FROM article_slugs h
+WHERE (h.slug = :base OR h.slug LIKE :base || '-%') AND h.article_id <> :article_id;
+
+-- name: GetCurrentSlug :one
+-- Resolves a former slug to the slug its article has now
+SELECT a.slug FROM article_slugs s
+JOIN articles a ON a.id = s.article_id
+WHERE s.slug = ? AND a.deleted_at IS NULL;
+
+-- name: AddSlugHistory :exec
+INSERT INTO article_slugs (slug, article_id, created_at)
+VALUES (:slug, :article_id, :created_at)
+ON CONFLICT (slug) DO UPDATE SET
+    article_id = excluded.article_id,
+    created_at = excluded.created_at;
+
+-- name: DeleteSlugHistory :exec
+DELETE FROM article_slugs WHERE slug = ?;
@@ -1,14 +1,94 @@
 -- name: CreateArticle :one
-INSERT INTO articles (name) VALUES (:name) RETURNING *;
+INSERT INTO articles (name, name_key, body, slug, status, created_at, updated_at, published_at)
+VALUES (
+    :name, :name_key, :body, :slug, :status, :now, :now,
+    CASE WHEN :status = 'published' THEN :now END
+)
+RETURNING *;
 
 -- name: GetArticle :one
+SELECT * FROM articles WHERE id = ? AND deleted_at IS NULL;
+
+-- name: GetArticleWithDeleted :one
 SELECT * FROM articles WHERE id = ?;
 
 -- name: ListArticles :many
-SELECT * FROM articles ORDER BY id;
+SELECT * FROM articles WHERE deleted_at IS NULL ORDER BY id;
+
+-- name: ListArticlesPage :many
+SELECT * FROM articles WHERE deleted_at IS NULL ORDER BY id LIMIT :limit OFFSET :offset;
+
+-- name: ListArticlesAfter :many
+SELECT * FROM articles WHERE id > :after_id AND deleted_at IS NULL ORDER BY id LIMIT :limit;
+
+-- name: ListDeletedArticles :many
+-- The trash, most recently deleted first
+SELECT * FROM articles WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT :limit OFFSET :offset;
 
 -- name: UpdateArticle :one
-UPDATE articles SET name = :name WHERE id = :id RETURNING *;
+-- published_at is set the first time an article becomes published and is
+-- cleared when it goes back to draft; archiving keeps it. No row is returned
+-- when the article is not at the expected version anymore.
+UPDATE articles SET
+    name = :name,
+    name_key = :name_key,
+    body = :body,
+    slug = :slug,
+    status = :status,
+    updated_at = :now,
+    published_at = CASE
+        WHEN :status = 'published' AND published_at IS NULL THEN :now
+        WHEN :status = 'draft' THEN NULL
+        ELSE published_at
+    END,
+    version = version + 1
+WHERE id = :id AND version = :version
+RETURNING *;
+
+-- name: SoftDeleteArticle :execrows
+-- Moves an article to the trash. The version check is skipped when no
+-- version is given.
+UPDATE articles SET
+    deleted_at = :now,
+    version = version + 1
+WHERE id = :id AND deleted_at IS NULL
+    AND version = coalesce(sqlc.narg(version), version);
+
+-- name: RestoreArticle :one
+UPDATE articles SET
+    deleted_at = NULL,
+    version = version + 1
+WHERE id = :id AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: DeleteArticle :execrows
+-- Deletes an article for good, whether it is in the trash or not
+DELETE FROM articles
+WHERE id = :id AND version = coalesce(sqlc.narg(version), version);
+
+-- name: PurgeDeletedArticles :execrows
+DELETE FROM articles WHERE deleted_at IS NOT NULL AND deleted_at < :before;
+
+-- name: GetArticleBySlug :one
+SELECT * FROM articles WHERE slug = CAST(:slug AS TEXT) AND deleted_at IS NULL;
 
--- name: DeleteArticle :exec
-DELETE FROM articles WHERE id = :id;
+-- name: PatchArticle :one
+-- Only the columns passed as non-NULL are written, the rest keep their values.
+-- Like UpdateArticle it only matches the expected version.
+UPDATE articles SET
+    name = coalesce(sqlc.narg(name), name),
+    name_key = coalesce(sqlc.narg(name_key), name_key),
+    body = coalesce(sqlc.narg(body), body),
+    slug = coalesce(sqlc.narg(slug), slug),
+    status = coalesce(sqlc.narg(status), status),
+    updated_at = sqlc.arg(now),
+    published_at = CASE
+        WHEN sqlc.narg(status) = 'published' AND published_at IS NULL THEN sqlc.arg(now)
+        WHEN sqlc.narg(status) = 'draft' THEN NULL
+        ELSE published_at
+    END,
+    version = version + 1
+WHERE id = sqlc.arg(id) AND version = sqlc.arg(version)
+RETURNING *;
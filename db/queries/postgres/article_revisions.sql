@@ -0,0 +1,12 @@
+-- name: CreateRevision :exec
+INSERT INTO article_revisions (article_id, rev, name, body, slug, status, created_at)
+VALUES (sqlc.arg(article_id), sqlc.arg(rev), sqlc.arg(name), sqlc.arg(body), sqlc.narg(slug), sqlc.arg(status), sqlc.arg(created_at));
+
+-- name: ListRevisions :many
+SELECT * FROM article_revisions
+WHERE article_id = sqlc.arg(article_id)
+ORDER BY rev DESC
+LIMIT sqlc.arg('limit')::bigint OFFSET sqlc.arg('offset')::bigint;
+
+-- name: GetRevision :one
+SELECT * FROM article_revisions WHERE article_id = sqlc.arg(article_id) AND rev = sqlc.arg(rev);
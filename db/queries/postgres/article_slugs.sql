@@ -0,0 +1,21 @@
+-- name: ListTakenSlugs :many
+SELECT a.slug::text AS slug FROM articles a
+WHERE (a.slug = sqlc.arg(base)::text OR a.slug LIKE sqlc.arg(base)::text || '-%') AND a.id <> sqlc.arg(article_id)
+UNION
+SELECT h.slug FROM article_slugs h
+WHERE (h.slug = sqlc.arg(base)::text OR h.slug LIKE sqlc.arg(base)::text || '-%') AND h.article_id <> sqlc.arg(article_id);
+
+-- name: GetCurrentSlug :one
+SELECT a.slug FROM article_slugs s
+JOIN articles a ON a.id = s.article_id
+WHERE s.slug = $1 AND a.deleted_at IS NULL;
+
+-- name: AddSlugHistory :exec
+INSERT INTO article_slugs (slug, article_id, created_at)
+VALUES (sqlc.arg(slug), sqlc.arg(article_id), sqlc.arg(created_at))
+ON CONFLICT (slug) DO UPDATE SET
+    article_id = excluded.article_id,
+    created_at = excluded.created_at;
+
+-- name: DeleteSlugHistory :exec
+DELETE FROM article_slugs WHERE slug = $1;
@@ -0,0 +1,91 @@
+-- The PostgreSQL versions of ../articles.sql, parameters are declared in the
+-- same order so that both engines generate the same Go signatures
+
+-- name: CreateArticle :one
+INSERT INTO articles (name, name_key, body, slug, status, created_at, updated_at, published_at)
+VALUES (
+    sqlc.arg(name), sqlc.arg(name_key), sqlc.arg(body), sqlc.narg(slug), sqlc.arg(status),
+    sqlc.arg(now)::timestamptz, sqlc.arg(now)::timestamptz,
+    CASE WHEN sqlc.arg(status) = 'published' THEN sqlc.arg(now)::timestamptz END
+)
+RETURNING *;
+
+-- name: GetArticle :one
+SELECT * FROM articles WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetArticleWithDeleted :one
+SELECT * FROM articles WHERE id = $1;
+
+-- name: ListArticles :many
+SELECT * FROM articles WHERE deleted_at IS NULL ORDER BY id;
+
+-- name: ListArticlesPage :many
+SELECT * FROM articles WHERE deleted_at IS NULL ORDER BY id
+LIMIT sqlc.arg('limit')::bigint OFFSET sqlc.arg('offset')::bigint;
+
+-- name: ListArticlesAfter :many
+SELECT * FROM articles WHERE id > sqlc.arg(after_id)::bigint AND deleted_at IS NULL ORDER BY id
+LIMIT sqlc.arg('limit')::bigint;
+
+-- name: ListDeletedArticles :many
+SELECT * FROM articles WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('limit')::bigint OFFSET sqlc.arg('offset')::bigint;
+
+-- name: UpdateArticle :one
+UPDATE articles SET
+    name = sqlc.arg(name),
+    name_key = sqlc.arg(name_key),
+    body = sqlc.arg(body),
+    slug = sqlc.narg(slug),
+    status = sqlc.arg(status),
+    updated_at = sqlc.arg(now)::timestamptz,
+    published_at = CASE
+        WHEN sqlc.arg(status) = 'published' AND published_at IS NULL THEN sqlc.arg(now)::timestamptz
+        WHEN sqlc.arg(status) = 'draft' THEN NULL
+        ELSE published_at
+    END,
+    version = version + 1
+WHERE id = sqlc.arg(id) AND version = sqlc.arg(version)
+RETURNING *;
+
+-- name: SoftDeleteArticle :execrows
+UPDATE articles SET
+    deleted_at = sqlc.narg(now),
+    version = version + 1
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+    AND version = coalesce(sqlc.narg(version)::bigint, version);
+
+-- name: RestoreArticle :one
+UPDATE articles SET
+    deleted_at = NULL,
+    version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: DeleteArticle :execrows
+DELETE FROM articles
+WHERE id = sqlc.arg(id) AND version = coalesce(sqlc.narg(version)::bigint, version);
+
+-- name: PurgeDeletedArticles :execrows
+DELETE FROM articles WHERE deleted_at IS NOT NULL AND deleted_at < sqlc.narg(before);
+
+-- name: GetArticleBySlug :one
+SELECT * FROM articles WHERE slug = sqlc.arg(slug)::text AND deleted_at IS NULL;
+
+-- name: PatchArticle :one
+UPDATE articles SET
+    name = coalesce(sqlc.narg(name), name),
+    name_key = coalesce(sqlc.narg(name_key), name_key),
+    body = coalesce(sqlc.narg(body), body),
+    slug = coalesce(sqlc.narg(slug), slug),
+    status = coalesce(sqlc.narg(status), status),
+    updated_at = sqlc.arg(now)::timestamptz,
+    published_at = CASE
+        WHEN sqlc.narg(status) = 'published' AND published_at IS NULL THEN sqlc.arg(now)::timestamptz
+        WHEN sqlc.narg(status) = 'draft' THEN NULL
+        ELSE published_at
+    END,
+    version = version + 1
+WHERE id = sqlc.arg(id) AND version = sqlc.arg(version)
+RETURNING *;
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/hexlet-components/go-gin-example/db/migrations"
+	"github.com/pressly/goose/v3"
+)
+
+// SchemaStatus compares the schema version of a database with the
+// migrations the binary has
+type SchemaStatus struct {
+	Version int64   `json:"version"`
+	Latest  int64   `json:"latest"`
+	Pending []int64 `json:"pending"`
+}
+
+// UpToDate tells whether every known migration has been applied
+func (s SchemaStatus) UpToDate() bool {
+	return len(s.Pending) == 0
+}
+
+// Ahead tells whether the database was migrated by a newer binary
+func (s SchemaStatus) Ahead() bool {
+	return s.Version > s.Latest
+}
+
+// MigrationVersions lists the versions of all known migrations in order
+func MigrationVersions(opts *MigrationOptions) ([]int64, error) {
+	if opts == nil {
+		opts = DefaultMigrationOptions()
+	}
+
+	all, err := migrationSources(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int64, 0, len(all))
+	for _, m := range all {
+		versions = append(versions, m.Version)
+	}
+	return versions, nil
+}
+
+// migrationSources lists the migrations of opts in order. Nothing is
+// connected to, the database is only needed to build the provider.
+func migrationSources(opts *MigrationOptions) ([]*goose.Source, error) {
+	database, err := openDB(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	p, err := newProvider(opts, database)
+	if err != nil {
+		return nil, err
+	}
+	return p.ListSources(), nil
+}
+
+// CheckSchema compares the schema version of database, written in dialect,
+// with the known migration versions. It only reads, so it is safe to call on
+// a live database.
+func CheckSchema(ctx context.Context, database *sql.DB, dialect string, versions []int64) (SchemaStatus, error) {
+	version, err := schemaVersion(ctx, database, dialect)
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+
+	status := SchemaStatus{Version: version, Pending: []int64{}}
+	for _, v := range versions {
+		status.Latest = max(status.Latest, v)
+		if v > version {
+			status.Pending = append(status.Pending, v)
+		}
+	}
+	return status, nil
+}
+
+// currentVersion reads the schema version without creating anything, a
+// missing database or version table means version 0
+func currentVersion(opts *MigrationOptions) (int64, error) {
+	dataSource := opts.dataSource()
+	if opts.Dialect == DialectSQLite {
+		if _, err := os.Stat(opts.DBFile); os.IsNotExist(err) {
+			return 0, nil
+		}
+		dataSource = sqliteURI(opts.DBFile) + "?mode=ro"
+	}
+
+	db, err := sql.Open(DriverName(opts.Dialect), dataSource)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open DB: %w", err)
+	}
+	defer db.Close()
+
+	return schemaVersion(context.Background(), db, opts.Dialect)
+}
+
+// schemaVersion reads the version goose has migrated database to, 0 when it
+// has not been migrated. The latest record of a version tells whether it is
+// applied, the same rule goose follows.
+func schemaVersion(ctx context.Context, database *sql.DB, dialect string) (int64, error) {
+	query := `SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if dialect == DialectPostgres {
+		query = `SELECT to_regclass($1) IS NOT NULL`
+	}
+	var exists bool
+	err := database.QueryRowContext(ctx, query, goose.DefaultTablename).Scan(&exists)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version int64
+	err = database.QueryRowContext(ctx, `
+		SELECT coalesce(max(version_id), 0) FROM goose_db_version AS v
+		WHERE is_applied AND id = (
+			SELECT max(id) FROM goose_db_version WHERE version_id = v.version_id
+		)`).Scan(&version)
+	return version, err
+}
+
+// SyncSearchIndex builds or repairs the full-text index of a SQLite database
+// to match what the binary supports, see migrations.SyncSearchIndex. Older
+// schemas keep the index their migrations gave them, the PostgreSQL one is
+// created by its migrations.
+func SyncSearchIndex(ctx context.Context, database *sql.DB, dialect string) error {
+	if dialect != DialectSQLite {
+		return nil
+	}
+	version, err := schemaVersion(ctx, database, dialect)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version < migrations.SearchIndexVersion {
+		return nil
+	}
+	if err := migrations.SyncSearchIndex(ctx, database); err != nil {
+		return fmt.Errorf("failed to sync search index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBusyTimeout is how long a SQLite connection waits for a lock held
+// by another connection, e.g. a CLI command writing while the server runs
+const DefaultBusyTimeout = 5 * time.Second
+
+// PoolOptions limit a connection pool, zero values keep the database/sql
+// defaults
+type PoolOptions struct {
+	MaxOpenConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// Apply sets the limits on database
+func (o PoolOptions) Apply(database *sql.DB) {
+	database.SetMaxOpenConns(o.MaxOpenConns)
+	database.SetMaxIdleConns(max(o.MaxOpenConns, 2))
+	database.SetConnMaxLifetime(o.ConnMaxLifetime)
+}
+
+// SQLiteOptions tune the connections OpenSQLite makes
+type SQLiteOptions struct {
+	// PoolOptions limit the readers, zero MaxOpenConns means one per CPU.
+	// The writer pool always has a single connection.
+	PoolOptions
+	// BusyTimeout is how long to wait for a lock before failing with
+	// "database is locked"
+	BusyTimeout time.Duration
+}
+
+// DefaultSQLiteOptions are the options the CLI starts with
+func DefaultSQLiteOptions() SQLiteOptions {
+	return SQLiteOptions{BusyTimeout: DefaultBusyTimeout}
+}
+
+// Pools is a database opened as a pool for writes and one for reads. They
+// are the same pool unless the database needs them apart, as SQLite does.
+type Pools struct {
+	Writer *sql.DB
+	Reader *sql.DB
+}
+
+// SinglePool makes Pools out of one pool for both reads and writes
+func SinglePool(database *sql.DB) *Pools {
+	return &Pools{Writer: database, Reader: database}
+}
+
+func (p *Pools) Close() error {
+	if p.Reader == p.Writer {
+		return p.Writer.Close()
+	}
+	return errors.Join(p.Writer.Close(), p.Reader.Close())
+}
+
+// sqlitePath escapes what SQLite would take for the start of the query or
+// fragment of a URI filename
+var sqlitePath = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
+// sqliteURI is the URI filename of the SQLite file at path, which keeps a
+// path with ?, # or % in it from being read as URI syntax
+func sqliteURI(path string) string {
+	return "file:" + sqlitePath.Replace(path)
+}
+
+// SQLiteDSN builds the data source of a connection to the SQLite file at
+// path. Every connection gets WAL, so readers never block the writer,
+// busy_timeout, enforced foreign keys and synchronous=NORMAL, which is safe
+// with WAL. Writers start transactions with BEGIN IMMEDIATE: taking the
+// write lock up front makes busy_timeout apply instead of failing when a
+// read transaction later turns into a write one. Readers are query-only.
+func SQLiteDSN(path string, opts SQLiteOptions, readOnly bool) string {
+	params := url.Values{}
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", strconv.FormatInt(opts.BusyTimeout.Milliseconds(), 10))
+	params.Set("_foreign_keys", "on")
+	params.Set("_synchronous", "NORMAL")
+	if readOnly {
+		params.Set("_query_only", "on")
+	} else {
+		params.Set("_txlock", "immediate")
+	}
+	return sqliteURI(path) + "?" + params.Encode()
+}
+
+// OpenSQLite opens the SQLite file at path as a single writer connection,
+// so that writes of the process queue up in Go rather than fight over the
+// file lock, and a pool of readers that WAL lets run alongside it
+func OpenSQLite(path string, opts SQLiteOptions) (*Pools, error) {
+	writer, err := sql.Open(DriverName(DialectSQLite), SQLiteDSN(path, opts, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DB: %w", err)
+	}
+	PoolOptions{MaxOpenConns: 1, ConnMaxLifetime: opts.ConnMaxLifetime}.Apply(writer)
+	// The writer switches the file to WAL before any reader connects
+	if err := writer.Ping(); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	reader, err := sql.Open(DriverName(DialectSQLite), SQLiteDSN(path, opts, true))
+	if err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open DB: %w", err)
+	}
+	readers := opts.PoolOptions
+	readers.MaxOpenConns = cmp.Or(readers.MaxOpenConns, runtime.NumCPU())
+	readers.Apply(reader)
+
+	return &Pools{Writer: writer, Reader: reader}, nil
+}
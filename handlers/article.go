@@ -1,32 +1,58 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/hexlet-components/go-gin-example/store"
+)
+
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
 )
 
 type ArticleParams struct {
-	Name string `json:"name" binding:"required,min=1,max=255"`
+	Name   string  `json:"name" binding:"required,min=1,max=255"`
+	Body   string  `json:"body" binding:"max=100000"`
+	Slug   *string `json:"slug" binding:"omitempty,min=1,max=255,slug"`
+	Status string  `json:"status" binding:"omitempty,oneof=draft published archived"`
 }
 
 type ArticleHandler struct {
-	Queries *db.Queries
+	Store store.ArticleStore
+	// AdminToken is the bearer token that allows hard deletes, they are
+	// disabled when it is empty
+	AdminToken string
 }
 
-func NewArticleHandler(queries *db.Queries) *ArticleHandler {
-	return &ArticleHandler{Queries: queries}
+func NewArticleHandler(articles store.ArticleStore) *ArticleHandler {
+	return &ArticleHandler{Store: articles}
 }
 
 func (h *ArticleHandler) Register(rg *gin.RouterGroup) {
 	rg.POST("", h.Create)
+	rg.GET("/search", h.Search)
+	rg.GET("/by-slug/:slug", h.GetBySlug)
+	rg.GET("/trash", h.Trash)
 	rg.GET("/:id", h.Get)
 	rg.GET("", h.List)
 	rg.PUT("/:id", h.Update)
+	rg.PATCH("/:id", h.Patch)
 	rg.DELETE("/:id", h.Delete)
+	rg.POST("/:id/restore", h.Restore)
+	rg.GET("/:id/revisions", h.ListRevisions)
+	rg.GET("/:id/revisions/:rev", h.GetRevision)
+	rg.GET("/:id/revisions/:rev/diff", h.DiffRevisions)
+	rg.POST("/:id/revisions/:rev/revert", h.RevertRevision)
 }
 
 func (h *ArticleHandler) Create(c *gin.Context) {
@@ -35,13 +61,29 @@ func (h *ArticleHandler) Create(c *gin.Context) {
 		return
 	}
 
-	article, err := h.Queries.CreateArticle(c, input.Name)
+	var article db.Article
+	err := h.Store.InTx(c.Request.Context(), func(q store.ArticleStore) error {
+		slug, err := articleSlug(c.Request.Context(), q, nil, input)
+		if err != nil {
+			return err
+		}
+
+		article, err = q.CreateArticle(c.Request.Context(), db.CreateArticleParams{
+			Name:    input.Name,
+			NameKey: textnorm.NameKey(input.Name),
+			Body:    input.Body,
+			Slug:    &slug,
+			Status:  input.Status,
+			Now:     time.Now().UTC(),
+		})
+		return err
+	})
 	if err != nil {
 		handleDBError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, article)
+	writeArticle(c, http.StatusCreated, article)
 }
 
 func (h *ArticleHandler) Get(c *gin.Context) {
@@ -51,22 +93,81 @@ func (h *ArticleHandler) Get(c *gin.Context) {
 		return
 	}
 
-	article, err := h.Queries.GetArticle(c, id)
+	article, err := h.Store.GetArticle(c.Request.Context(), id)
 	if err != nil {
 		handleDBError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, article)
+	writeArticle(c, http.StatusOK, article)
 }
 
 func (h *ArticleHandler) List(c *gin.Context) {
-	articles, err := h.Queries.ListArticles(c)
+	page, err := parsePageRequest(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	query, err := parseListQuery(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	articles, err := h.listArticles(c, query, page)
+	if errors.Is(err, ErrorInvalidCursor) {
+		badRequest(c, err)
+		return
+	}
 	if err != nil {
 		handleDBError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, articles)
+
+	result := Page[db.Article]{Items: []db.Article{}}
+	var next *cursor
+	if int64(len(articles)) > page.Limit {
+		articles = articles[:page.Limit]
+		next = query.cursorFor(articles[len(articles)-1])
+		encoded := next.encode()
+		result.NextCursor = &encoded
+	}
+	result.Items = append(result.Items, articles...)
+
+	setLinkHeader(c, page, next != nil, next)
+	c.JSON(http.StatusOK, result)
+}
+
+// listArticles fetches one row more than the page limit so the caller can
+// tell whether there is a next page
+func (h *ArticleHandler) listArticles(c *gin.Context, query listQuery, page pageRequest) ([]db.Article, error) {
+	if query.isDefault() {
+		if page.After == nil {
+			return h.Store.ListArticlesPage(c.Request.Context(), db.ListArticlesPageParams{
+				Offset: page.Offset,
+				Limit:  page.Limit + 1,
+			})
+		}
+
+		if page.After.Sort != query.sortString() || len(page.After.Values) != 1 {
+			return nil, ErrorInvalidCursor
+		}
+		afterID, err := strconv.ParseInt(page.After.Values[0], 10, 64)
+		if err != nil {
+			return nil, ErrorInvalidCursor
+		}
+		return h.Store.ListArticlesAfter(c.Request.Context(), db.ListArticlesAfterParams{
+			AfterID: afterID,
+			Limit:   page.Limit + 1,
+		})
+	}
+
+	sq, err := query.storeQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	return h.Store.FindArticles(c.Request.Context(), sq)
 }
 
 func (h *ArticleHandler) Update(c *gin.Context) {
@@ -81,20 +182,58 @@ func (h *ArticleHandler) Update(c *gin.Context) {
 		return
 	}
 
-	updateParams := db.UpdateArticleParams{
-		ID:   id,
-		Name: input.Name,
-	}
+	var article db.Article
+	err = h.Store.InTx(c.Request.Context(), func(q store.ArticleStore) error {
+		current, err := q.GetArticle(c.Request.Context(), id)
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(c, current); err != nil {
+			return err
+		}
 
-	article, err := h.Queries.UpdateArticle(c, updateParams)
+		article, err = updateArticle(c, q, current, input)
+		return err
+	})
 	if err != nil {
 		handleDBError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, article)
+	writeArticle(c, http.StatusOK, article)
 }
 
+// updateArticle replaces the writable fields of current with input, keeping
+// its previous state as a revision and its previous slug as a redirect
+func updateArticle(c *gin.Context, q store.ArticleStore, current db.Article, input ArticleParams) (db.Article, error) {
+	slug, err := articleSlug(c.Request.Context(), q, &current, input)
+	if err != nil {
+		return db.Article{}, err
+	}
+
+	now := time.Now().UTC()
+	if err := saveSlugHistory(c.Request.Context(), q, current, slug, now); err != nil {
+		return db.Article{}, err
+	}
+	if err := saveRevision(c.Request.Context(), q, current); err != nil {
+		return db.Article{}, err
+	}
+
+	article, err := q.UpdateArticle(c.Request.Context(), db.UpdateArticleParams{
+		ID:      current.ID,
+		Name:    input.Name,
+		NameKey: textnorm.NameKey(input.Name),
+		Body:    input.Body,
+		Slug:    &slug,
+		Status:  input.Status,
+		Now:     now,
+		Version: current.Version,
+	})
+	return article, updateFailed(err)
+}
+
+// Delete moves an article to the trash. Admins can pass ?hard=true to delete
+// it for good, including articles that are already in the trash.
 func (h *ArticleHandler) Delete(c *gin.Context) {
 	id, err := h.parseID(c)
 	if err != nil {
@@ -102,7 +241,24 @@ func (h *ArticleHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.Queries.DeleteArticle(c, id)
+	hard, err := h.parseHard(c)
+	if errors.Is(err, ErrorAdminRequired) {
+		forbidden(c, err)
+		return
+	}
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	// Without If-Match the delete is unconditional and idempotent
+	if c.GetHeader("If-Match") == "" {
+		_, err = removeArticle(c, h.Store, id, hard, sql.NullInt64{})
+	} else {
+		err = h.Store.InTx(c.Request.Context(), func(q store.ArticleStore) error {
+			return deleteIfMatch(c, q, id, hard)
+		})
+	}
 	if err != nil {
 		handleDBError(c, err)
 		return
@@ -123,15 +279,28 @@ func (h *ArticleHandler) parseAndValidateParams(c *gin.Context) (ArticleParams,
 	var params ArticleParams
 
 	if err := c.ShouldBindJSON(&params); err != nil {
+		badRequest(c, translateBindingError(c, err))
+		return ArticleParams{}, false
+	}
+
+	if err := normalizeParams(&params); err != nil {
 		badRequest(c, err)
 		return ArticleParams{}, false
 	}
 
+	return params, true
+}
+
+// normalizeParams trims the name and fills in the default status
+func normalizeParams(params *ArticleParams) error {
 	params.Name = strings.TrimSpace(params.Name)
 	if len(params.Name) == 0 {
-		badRequest(c, ErrorNameEmpty)
-		return ArticleParams{}, false
+		return ErrorNameEmpty
 	}
 
-	return params, true
+	if params.Status == "" {
+		params.Status = StatusDraft
+	}
+
+	return nil
 }
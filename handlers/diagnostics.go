@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"cmp"
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	migrate "github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/internal/buildinfo"
+)
+
+// Diagnostics describes the running binary and the schema of its database,
+// if it has one
+type Diagnostics struct {
+	Build  buildinfo.Info        `json:"build"`
+	Schema *migrate.SchemaStatus `json:"schema,omitempty"`
+}
+
+type DiagnosticsHandler struct {
+	// DB is nil for stores without a database
+	DB *sql.DB
+	// Dialect is the dialect of DB, empty means SQLite
+	Dialect string
+	// Migrations are the versions the binary has, the schema of DB is
+	// compared with them
+	Migrations []int64
+}
+
+func (h *DiagnosticsHandler) Get(c *gin.Context) {
+	if h.DB == nil {
+		c.JSON(http.StatusOK, Diagnostics{Build: buildinfo.Read()})
+		return
+	}
+
+	schema, err := migrate.CheckSchema(c.Request.Context(), h.DB, cmp.Or(h.Dialect, migrate.DialectSQLite), h.Migrations)
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Diagnostics{Build: buildinfo.Read(), Schema: &schema})
+}
@@ -3,9 +3,11 @@ package handlers
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hexlet-components/go-gin-example/store"
 )
 
 var (
@@ -13,15 +15,77 @@ var (
 	ErrorNameEmpty     = errors.New("name cannot be empty")
 	ErrorNameTooLong   = errors.New("name is too long")
 	ErrorArticleExists = errors.New("article already exists")
+
+	ErrorValidationFailed = errors.New("validation failed")
+
+	ErrorArticleNotFound = errors.New("article not found")
+	ErrorRouteNotFound   = errors.New("no route matches the request")
+
+	ErrorInvalidLimit       = errors.New("limit must be an integer between 1 and 100")
+	ErrorInvalidOffset      = errors.New("offset must be a non-negative integer")
+	ErrorInvalidCursor      = errors.New("invalid cursor")
+	ErrorPaginationConflict = errors.New("offset and after cannot be used together")
+
+	ErrorUnknownField       = errors.New("unknown field")
+	ErrorUnknownOperator    = errors.New("unknown operator")
+	ErrorInvalidFilterValue = errors.New("invalid filter value")
+	ErrorDuplicateSortField = errors.New("duplicate sort field")
+	ErrorUnsortableField    = errors.New("field cannot be sorted on")
+
+	ErrorSearchQueryEmpty   = errors.New("search query cannot be empty")
+	ErrorCursorNotSupported = errors.New("cursor pagination is not supported here, use offset")
+	ErrorSearchUnavailable  = store.ErrSearchUnavailable
+
+	ErrorUnsupportedPatchType = errors.New("patch must be application/merge-patch+json or application/json-patch+json")
+	ErrorInvalidPatch         = errors.New("malformed patch document")
+	ErrorPatchFailed          = errors.New("patch cannot be applied")
+
+	ErrorPreconditionFailed = errors.New("article has changed since it was read, If-Match does not match its ETag")
+
+	ErrorInvalidBoolean    = errors.New("must be true or false")
+	ErrorAdminRequired     = errors.New("hard delete requires an admin token")
+	ErrorArticleNotInTrash = errors.New("article is not in the trash")
+
+	ErrorInvalidRevision  = errors.New("revision must be a positive integer")
+	ErrorRevisionNotFound = errors.New("revision not found")
 )
 
+// FieldError ties an error to the request body field that caused it
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
 func handleDBError(c *gin.Context, err error) {
 	if err == nil {
 		return
 	}
 
 	if errors.Is(err, sql.ErrNoRows) {
-		notFound(c)
+		notFound(c, ErrorArticleNotFound)
+		return
+	}
+
+	if errors.Is(err, ErrorPreconditionFailed) {
+		preconditionFailed(c, err)
+		return
+	}
+
+	if errors.Is(err, ErrorArticleExists) {
+		conflict(c, err)
+		return
+	}
+
+	if field, ok := store.UniqueViolation(err); ok {
+		conflict(c, &FieldError{Field: field, Err: ErrorArticleExists})
 		return
 	}
 
@@ -29,36 +93,38 @@ func handleDBError(c *gin.Context, err error) {
 }
 
 func badRequest(c *gin.Context, err error) {
-	c.JSON(http.StatusBadRequest, gin.H{
-		"error":   "Bad Request",
-		"message": err.Error(),
-	})
+	problem(c, http.StatusBadRequest, err)
 }
 
-func notFound(c *gin.Context) {
-	c.JSON(http.StatusNotFound, gin.H{
-		"error":   "Not Found",
-		"message": "Resource not found",
-	})
+func notFound(c *gin.Context, err error) {
+	problem(c, http.StatusNotFound, err)
 }
 
 func internalServerError(c *gin.Context, err error) {
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"error":   "Internal Server Error",
-		"message": "Something went wrong",
-	})
+	_ = c.Error(err)
+	problem(c, http.StatusInternalServerError, err)
 }
 
 func conflict(c *gin.Context, err error) {
-	c.JSON(http.StatusConflict, gin.H{
-		"error":   "Conflict",
-		"message": err.Error(),
-	})
+	problem(c, http.StatusConflict, err)
+}
+
+func notImplemented(c *gin.Context, err error) {
+	problem(c, http.StatusNotImplemented, err)
 }
 
 func unprocessableEntity(c *gin.Context, err error) {
-	c.JSON(http.StatusUnprocessableEntity, gin.H{
-		"error":   "Unprocessable Entity",
-		"message": err.Error(),
-	})
+	problem(c, http.StatusUnprocessableEntity, err)
+}
+
+func unsupportedMediaType(c *gin.Context, err error) {
+	problem(c, http.StatusUnsupportedMediaType, err)
+}
+
+func preconditionFailed(c *gin.Context, err error) {
+	problem(c, http.StatusPreconditionFailed, err)
+}
+
+func forbidden(c *gin.Context, err error) {
+	problem(c, http.StatusForbidden, err)
 }
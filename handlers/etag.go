@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/store"
+)
+
+// articleETag is a strong validator of an article: every write bumps the
+// version, so equal versions mean byte-identical representations
+func articleETag(article db.Article) string {
+	return `"` + strconv.FormatInt(article.Version, 10) + `"`
+}
+
+// matchETag reports whether an If-Match or If-None-Match header lists etag.
+// Weak comparison (If-None-Match) ignores the W/ prefix, strong comparison
+// (If-Match) never matches a weak tag.
+func matchETag(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch fails with ErrorPreconditionFailed when the request has an
+// If-Match header that does not list the current ETag of the article
+func checkIfMatch(c *gin.Context, current db.Article) error {
+	header := c.GetHeader("If-Match")
+	if header != "" && !matchETag(header, articleETag(current), false) {
+		return ErrorPreconditionFailed
+	}
+	return nil
+}
+
+// updateFailed tells a lost race from other errors: the article was read
+// earlier in the transaction, so a conditional update that matched no row
+// means its version has moved on
+func updateFailed(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrorPreconditionFailed
+	}
+	return err
+}
+
+// deleteIfMatch removes an article only when it is at a version listed in
+// If-Match. A missing article fails the precondition too (RFC 9110 13.1.1).
+func deleteIfMatch(c *gin.Context, q store.ArticleStore, id int64, hard bool) error {
+	get := q.GetArticle
+	if hard {
+		get = q.GetArticleWithDeleted
+	}
+
+	current, err := get(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrorPreconditionFailed
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(c, current); err != nil {
+		return err
+	}
+
+	removed, err := removeArticle(c, q, id, hard, sql.NullInt64{Int64: current.Version, Valid: true})
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrorPreconditionFailed
+	}
+	return nil
+}
+
+// writeArticle sends an article with its ETag. A GET whose If-None-Match
+// lists that ETag gets an empty 304 Not Modified instead.
+func writeArticle(c *gin.Context, status int, article db.Article) {
+	etag := articleETag(article)
+	c.Header("ETag", etag)
+
+	method := c.Request.Method
+	if method == http.MethodGet || method == http.MethodHead {
+		if header := c.GetHeader("If-None-Match"); header != "" && matchETag(header, etag, true) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.JSON(status, article)
+}
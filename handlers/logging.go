@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const loggerKey = "logger"
+
+// RequestLogger gives every request an id, echoed in X-Request-ID, and a
+// logger carrying it that handlers get with Logger. Once the request is
+// served it logs one record with the route, status, latency and bytes.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Set(loggerKey, logger.With(slog.String("request_id", requestID(c))))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			// The route template keeps the records of /articles/1 and
+			// /articles/2 together, unmatched requests have none
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if err := c.Errors.Last(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		Logger(c).LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Logger is the logger of the request, slog.Default() outside of
+// RequestLogger
+func Logger(c *gin.Context) *slog.Logger {
+	if logger, ok := c.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// recovery turns a panic into a 500 problem and logs it with its stack
+// instead of letting gin print it as text
+func recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		Logger(c).ErrorContext(c.Request.Context(), "panic serving request",
+			slog.Any("panic", recovered), slog.String("stack", string(debug.Stack())))
+		internalServerError(c, fmt.Errorf("panic: %v", recovered))
+	})
+}
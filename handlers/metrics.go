@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"cmp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hexlet-components/go-gin-example/internal/metrics"
+)
+
+// RequestMetrics counts the requests in flight and records every served
+// request by its route template. Requests no route matched share the
+// unmatched route, so that scanners cannot grow the number of series.
+func RequestMetrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.StartRequest()
+		c.Next()
+		done(c.Request.Method, cmp.Or(c.FullPath(), "unmatched"), c.Writer.Status())
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageRequest describes which slice of a collection the client asked for.
+// Either Offset or After is used, never both.
+type pageRequest struct {
+	Limit  int64
+	Offset int64
+	After  *cursor
+}
+
+// cursor is the decoded form of the opaque `after` query parameter. It keeps
+// the sort it was issued for and the sort key values of the last row served.
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func (c cursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrorInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || len(c.Values) == 0 {
+		return nil, ErrorInvalidCursor
+	}
+	return &c, nil
+}
+
+func parsePageRequest(c *gin.Context) (pageRequest, error) {
+	page := pageRequest{Limit: defaultPageLimit}
+
+	if s, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return pageRequest{}, ErrorInvalidLimit
+		}
+		page.Limit = limit
+	}
+
+	offset, hasOffset := c.GetQuery("offset")
+	after, hasAfter := c.GetQuery("after")
+	if hasOffset && hasAfter {
+		return pageRequest{}, ErrorPaginationConflict
+	}
+
+	if hasOffset {
+		n, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil || n < 0 {
+			return pageRequest{}, ErrorInvalidOffset
+		}
+		page.Offset = n
+	}
+
+	if hasAfter {
+		cur, err := decodeCursor(after)
+		if err != nil {
+			return pageRequest{}, err
+		}
+		page.After = cur
+	}
+
+	return page, nil
+}
+
+// Page is the response envelope for paginated collections
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// setLinkHeader writes an RFC 8288 Link header pointing at the neighbouring
+// pages. Cursor requests only get first/next, offset requests also get prev.
+// next is only used for cursor requests and may be nil otherwise.
+func setLinkHeader(c *gin.Context, page pageRequest, hasNext bool, next *cursor) {
+	var links []string
+
+	link := func(rel string, set func(q url.Values)) {
+		q := c.Request.URL.Query()
+		q.Del("offset")
+		q.Del("after")
+		q.Set("limit", strconv.FormatInt(page.Limit, 10))
+		set(q)
+		u := url.URL{Path: c.Request.URL.Path, RawQuery: q.Encode()}
+		links = append(links, fmt.Sprintf("<%s>; rel=\"%s\"", u.String(), rel))
+	}
+
+	link("first", func(url.Values) {})
+
+	if page.After == nil && page.Offset > 0 {
+		prev := max(page.Offset-page.Limit, 0)
+		link("prev", func(q url.Values) {
+			q.Set("offset", strconv.FormatInt(prev, 10))
+		})
+	}
+
+	if hasNext {
+		if page.After != nil {
+			link("next", func(q url.Values) {
+				q.Set("after", next.encode())
+			})
+		} else {
+			link("next", func(q url.Values) {
+				q.Set("offset", strconv.FormatInt(page.Offset+page.Limit, 10))
+			})
+		}
+	}
+
+	c.Header("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/hexlet-components/go-gin-example/store"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// patchFunc applies a patch document to the JSON form of an article
+type patchFunc func(doc []byte) ([]byte, error)
+
+// Patch applies an RFC 7396 merge patch or an RFC 6902 JSON patch, picked by
+// Content-Type, to the writable fields of an article. The result is validated
+// like a create request and only the columns that changed are written.
+func (h *ArticleHandler) Patch(c *gin.Context) {
+	id, err := h.parseID(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	apply, err := parsePatch(c)
+	if err != nil {
+		if errors.Is(err, ErrorUnsupportedPatchType) {
+			unsupportedMediaType(c, err)
+		} else {
+			badRequest(c, err)
+		}
+		return
+	}
+
+	var article db.Article
+	var invalid error
+	err = h.Store.InTx(c.Request.Context(), func(q store.ArticleStore) error {
+		current, err := q.GetArticle(c.Request.Context(), id)
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(c, current); err != nil {
+			return err
+		}
+
+		input, err := applyPatch(current, apply)
+		if err != nil {
+			invalid = err
+			return err
+		}
+		if err := validateParams(c, &input); err != nil {
+			invalid = err
+			return err
+		}
+
+		article, err = h.patchArticle(c, q, current, input)
+		return err
+	})
+
+	switch {
+	case invalid != nil && errors.Is(invalid, ErrorPatchFailed):
+		unprocessableEntity(c, invalid)
+	case invalid != nil:
+		badRequest(c, invalid)
+	case err != nil:
+		handleDBError(c, err)
+	default:
+		writeArticle(c, http.StatusOK, article)
+	}
+}
+
+func parsePatch(c *gin.Context) (patchFunc, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mediaType {
+	case MergePatchContentType:
+		if !json.Valid(body) {
+			return nil, ErrorInvalidPatch
+		}
+		return func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, body)
+		}, nil
+	case JSONPatchContentType:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, ErrorInvalidPatch
+		}
+		return patch.Apply, nil
+	default:
+		return nil, ErrorUnsupportedPatchType
+	}
+}
+
+// applyPatch patches the writable fields of current. The document has the
+// same shape as a PUT body, so patch paths are /name, /body, /slug, /status.
+func applyPatch(current db.Article, apply patchFunc) (ArticleParams, error) {
+	doc, err := json.Marshal(ArticleParams{
+		Name:   current.Name,
+		Body:   current.Body,
+		Slug:   current.Slug,
+		Status: current.Status,
+	})
+	if err != nil {
+		return ArticleParams{}, err
+	}
+
+	patched, err := apply(doc)
+	if err != nil {
+		return ArticleParams{}, errors.Join(ErrorPatchFailed, err)
+	}
+
+	var input ArticleParams
+	if err := json.Unmarshal(patched, &input); err != nil {
+		return ArticleParams{}, errors.Join(ErrorPatchFailed, err)
+	}
+	return input, nil
+}
+
+// patchArticle writes the fields of input that differ from current. A slug
+// left untouched by the patch follows the name like on PUT.
+func (h *ArticleHandler) patchArticle(c *gin.Context, q store.ArticleStore, current db.Article, input ArticleParams) (db.Article, error) {
+	if input.Slug != nil && current.Slug != nil && *input.Slug == *current.Slug {
+		input.Slug = nil
+	}
+	slug, err := articleSlug(c.Request.Context(), q, &current, input)
+	if err != nil {
+		return db.Article{}, err
+	}
+
+	var params db.PatchArticleParams
+	changed := false
+	if input.Name != current.Name {
+		params.Name = sql.NullString{String: input.Name, Valid: true}
+		params.NameKey = sql.NullString{String: textnorm.NameKey(input.Name), Valid: true}
+		changed = true
+	}
+	if input.Body != current.Body {
+		params.Body = sql.NullString{String: input.Body, Valid: true}
+		changed = true
+	}
+	if current.Slug == nil || slug != *current.Slug {
+		params.Slug = &slug
+		changed = true
+	}
+	if input.Status != current.Status {
+		params.Status = sql.NullString{String: input.Status, Valid: true}
+		changed = true
+	}
+	if !changed {
+		return current, nil
+	}
+
+	params.ID = current.ID
+	params.Version = current.Version
+	params.Now = time.Now().UTC()
+	if err := saveSlugHistory(c.Request.Context(), q, current, slug, params.Now); err != nil {
+		return db.Article{}, err
+	}
+	if err := saveRevision(c.Request.Context(), q, current); err != nil {
+		return db.Article{}, err
+	}
+	article, err := q.PatchArticle(c.Request.Context(), params)
+	return article, updateFailed(err)
+}
+
+// validateParams applies the binding rules and the normalization of
+// parseAndValidateParams to a body that did not come from binding
+func validateParams(c *gin.Context, params *ArticleParams) error {
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		return translateBindingError(c, err)
+	}
+	return normalizeParams(params)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ProblemContentType is the media type of RFC 9457 error documents
+	ProblemContentType = "application/problem+json"
+
+	// RequestIDHeader carries the id that correlates a request with its logs
+	RequestIDHeader = "X-Request-ID"
+
+	// ProblemTypeBase prefixes every problem type URI. Tag URIs (RFC 4151)
+	// are stable identifiers that are not expected to be dereferenced.
+	ProblemTypeBase = "tag:hexlet.io,2026:go-gin-example/problems/"
+
+	requestIDKey = "request_id"
+)
+
+// ProblemType is an entry of the problem type registry. Its URI is the stable
+// identifier clients switch on, the title never changes between occurrences.
+type ProblemType struct {
+	URI   string
+	Title string
+}
+
+func problemType(slug, title string) ProblemType {
+	return ProblemType{URI: ProblemTypeBase + slug, Title: title}
+}
+
+// problemTypes maps sentinel errors to their problem types. Errors are matched
+// with errors.Is, so wrapped sentinels resolve to the same type.
+var problemTypes = []struct {
+	err  error
+	kind ProblemType
+}{
+	{ErrorInvalidID, problemType("invalid-id", "Invalid article id")},
+	{ErrorNameEmpty, problemType("name-empty", "Article name is empty")},
+	{ErrorNameTooLong, problemType("name-too-long", "Article name is too long")},
+	{ErrorArticleExists, problemType("article-exists", "Article already exists")},
+	{ErrorValidationFailed, problemType("validation-failed", "Request body failed validation")},
+	{ErrorArticleNotFound, problemType("article-not-found", "Article not found")},
+	{ErrorRouteNotFound, problemType("route-not-found", "Route not found")},
+	{ErrorInvalidLimit, problemType("invalid-limit", "Invalid page limit")},
+	{ErrorInvalidOffset, problemType("invalid-offset", "Invalid page offset")},
+	{ErrorInvalidCursor, problemType("invalid-cursor", "Invalid page cursor")},
+	{ErrorPaginationConflict, problemType("pagination-conflict", "Conflicting pagination parameters")},
+	{ErrorUnknownField, problemType("unknown-field", "Unknown field")},
+	{ErrorUnknownOperator, problemType("unknown-operator", "Unknown filter operator")},
+	{ErrorInvalidFilterValue, problemType("invalid-filter-value", "Invalid filter value")},
+	{ErrorDuplicateSortField, problemType("duplicate-sort-field", "Duplicate sort field")},
+	{ErrorUnsortableField, problemType("unsortable-field", "Field cannot be sorted on")},
+	{ErrorSearchQueryEmpty, problemType("search-query-empty", "Search query is empty")},
+	{ErrorCursorNotSupported, problemType("cursor-not-supported", "Cursor pagination is not supported")},
+	{ErrorSearchUnavailable, problemType("search-unavailable", "Full-text search is unavailable")},
+	{ErrorUnsupportedPatchType, problemType("unsupported-patch-type", "Unsupported patch format")},
+	{ErrorInvalidPatch, problemType("invalid-patch", "Malformed patch document")},
+	{ErrorPatchFailed, problemType("patch-failed", "Patch cannot be applied")},
+	{ErrorPreconditionFailed, problemType("precondition-failed", "Article has changed")},
+	{ErrorInvalidBoolean, problemType("invalid-boolean", "Invalid boolean parameter")},
+	{ErrorAdminRequired, problemType("admin-required", "Admin access required")},
+	{ErrorArticleNotInTrash, problemType("article-not-in-trash", "Article is not in the trash")},
+	{ErrorInvalidRevision, problemType("invalid-revision", "Invalid revision number")},
+	{ErrorRevisionNotFound, problemType("revision-not-found", "Revision not found")},
+}
+
+// ProblemTypeOf returns the registered problem type of err. Unregistered
+// errors get "about:blank", whose title is the HTTP status phrase.
+func ProblemTypeOf(err error, status int) ProblemType {
+	for _, entry := range problemTypes {
+		if errors.Is(err, entry.err) {
+			return entry.kind
+		}
+	}
+	return ProblemType{URI: "about:blank", Title: http.StatusText(status)}
+}
+
+// Problem is an RFC 9457 problem details document
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// RequestID is an extension member that matches the X-Request-ID header
+	RequestID string `json:"request_id,omitempty"`
+	// Param and Field are extension members pointing at the offending input
+	Param string `json:"param,omitempty"`
+	Field string `json:"field,omitempty"`
+	// Errors lists every failed rule when the request body is invalid
+	Errors []FieldViolation `json:"errors,omitempty"`
+}
+
+// problem writes err as a problem details response. The detail is the error
+// message, except for internal errors whose messages are not meant for clients.
+func problem(c *gin.Context, status int, err error) {
+	kind := ProblemTypeOf(err, status)
+	p := Problem{
+		Type:      kind.URI,
+		Title:     kind.Title,
+		Status:    status,
+		Instance:  c.Request.URL.Path,
+		RequestID: requestID(c),
+	}
+
+	if status != http.StatusInternalServerError {
+		p.Detail = err.Error()
+	}
+
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		p.Param = queryErr.Param
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		p.Field = fieldErr.Field
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		p.Errors = validationErr.Violations
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(status, p)
+}
+
+// requestID returns the id of the current request, taking it from the
+// X-Request-ID header when the client or a proxy supplied one
+func requestID(c *gin.Context) string {
+	if id := c.GetString(requestIDKey); id != "" {
+		return id
+	}
+
+	id := c.GetHeader(RequestIDHeader)
+	if id == "" || len(id) > 128 {
+		buf := make([]byte, 16)
+		_, _ = rand.Read(buf)
+		id = hex.EncodeToString(buf)
+	}
+
+	c.Set(requestIDKey, id)
+	c.Header(RequestIDHeader, id)
+	return id
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/store"
+)
+
+// QueryError reports which query parameter made a list request invalid
+type QueryError struct {
+	Param string
+	Err   error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query parameter %q: %v", e.Param, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+type fieldKind int
+
+const (
+	kindInt fieldKind = iota
+	kindText
+	kindTime
+)
+
+// listField is a store field clients may filter and sort on. Nullable
+// fields have no value func: NULLs break keyset pagination, so they can be
+// filtered on but not sorted by.
+type listField struct {
+	kind  fieldKind
+	value func(db.Article) string
+}
+
+func (f listField) sortable() bool {
+	return f.value != nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+var articleFields = map[string]listField{
+	"id": {
+		kind:  kindInt,
+		value: func(a db.Article) string { return strconv.FormatInt(a.ID, 10) },
+	},
+	"name": {
+		kind:  kindText,
+		value: func(a db.Article) string { return a.Name },
+	},
+	"body": {
+		kind: kindText,
+	},
+	"slug": {
+		kind: kindText,
+	},
+	"status": {
+		kind:  kindText,
+		value: func(a db.Article) string { return a.Status },
+	},
+	"created_at": {
+		kind:  kindTime,
+		value: func(a db.Article) string { return formatTime(a.CreatedAt) },
+	},
+	"updated_at": {
+		kind:  kindTime,
+		value: func(a db.Article) string { return formatTime(a.UpdatedAt) },
+	},
+	"published_at": {
+		kind: kindTime,
+	},
+}
+
+// filterOperators lists the field kinds each store operator applies to
+var filterOperators = map[string][]fieldKind{
+	store.OpEq:       {kindInt, kindText, kindTime},
+	store.OpNe:       {kindInt, kindText, kindTime},
+	store.OpGt:       {kindInt, kindText, kindTime},
+	store.OpGte:      {kindInt, kindText, kindTime},
+	store.OpLt:       {kindInt, kindText, kindTime},
+	store.OpLte:      {kindInt, kindText, kindTime},
+	store.OpContains: {kindText},
+	store.OpPrefix:   {kindText},
+	store.OpIn:       {kindInt, kindText},
+}
+
+var reservedParams = []string{"limit", "offset", "after", "sort"}
+
+// listQuery is a validated `?sort=-name,id&name[contains]=go` request
+type listQuery struct {
+	Sort    []store.SortKey
+	Filters []store.Filter
+}
+
+func parseListQuery(c *gin.Context) (listQuery, error) {
+	var q listQuery
+
+	sortKeys, err := parseSort(c.Query("sort"))
+	if err != nil {
+		return listQuery{}, &QueryError{Param: "sort", Err: err}
+	}
+	q.Sort = sortKeys
+
+	params := c.Request.URL.Query()
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if slices.Contains(reservedParams, key) {
+			continue
+		}
+		for _, raw := range params[key] {
+			filter, err := parseFilter(key, raw)
+			if err != nil {
+				return listQuery{}, &QueryError{Param: key, Err: err}
+			}
+			q.Filters = append(q.Filters, filter)
+		}
+	}
+
+	return q, nil
+}
+
+// parseSort parses a comma-separated list of fields, a leading "-" meaning
+// descending order. The id is always appended as a tie-breaker so that the
+// order is total and can be paginated with a cursor.
+func parseSort(s string) ([]store.SortKey, error) {
+	var keys []store.SortKey
+	seen := map[string]bool{}
+
+	if s != "" {
+		for part := range strings.SplitSeq(s, ",") {
+			key := store.SortKey{Field: strings.TrimSpace(part)}
+			if after, ok := strings.CutPrefix(key.Field, "-"); ok {
+				key.Field, key.Desc = after, true
+			}
+			field, ok := articleFields[key.Field]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrorUnknownField, key.Field)
+			}
+			if !field.sortable() {
+				return nil, fmt.Errorf("%w: %q", ErrorUnsortableField, key.Field)
+			}
+			if seen[key.Field] {
+				return nil, fmt.Errorf("%w: %q", ErrorDuplicateSortField, key.Field)
+			}
+			seen[key.Field] = true
+			keys = append(keys, key)
+		}
+	}
+
+	if !seen["id"] {
+		keys = append(keys, store.SortKey{Field: "id"})
+	}
+	return keys, nil
+}
+
+// parseFilter parses a single `field[op]=value` pair, `field=value` being a
+// shorthand for `field[eq]=value`
+func parseFilter(key, raw string) (store.Filter, error) {
+	name, op := key, store.OpEq
+	if i := strings.IndexByte(key, '['); i >= 0 && strings.HasSuffix(key, "]") {
+		name, op = key[:i], key[i+1:len(key)-1]
+	}
+
+	field, ok := articleFields[name]
+	if !ok {
+		return store.Filter{}, fmt.Errorf("%w: %q", ErrorUnknownField, name)
+	}
+	kinds, ok := filterOperators[op]
+	if !ok || !slices.Contains(kinds, field.kind) {
+		return store.Filter{}, fmt.Errorf("%w: %q for field %q", ErrorUnknownOperator, op, name)
+	}
+
+	values := []string{raw}
+	if op == store.OpIn {
+		values = strings.Split(raw, ",")
+	}
+
+	args := make([]any, 0, len(values))
+	for _, v := range values {
+		arg, err := field.parse(v)
+		if err != nil {
+			return store.Filter{}, err
+		}
+		args = append(args, arg)
+	}
+
+	return store.Filter{Field: name, Op: op, Values: args}, nil
+}
+
+// parse converts a query string value to the Go type stored in the column.
+// Times are accepted as RFC 3339 timestamps or plain dates and bound in UTC,
+// which is how the handlers write them.
+func (f listField) parse(s string) (any, error) {
+	switch f.kind {
+	case kindInt:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not an integer", ErrorInvalidFilterValue, s)
+		}
+		return n, nil
+	case kindTime:
+		for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q is not an RFC 3339 timestamp or a date", ErrorInvalidFilterValue, s)
+	default:
+		return s, nil
+	}
+}
+
+func (q listQuery) sortString() string {
+	parts := make([]string, 0, len(q.Sort))
+	for _, key := range q.Sort {
+		if key.Desc {
+			parts = append(parts, "-"+key.Field)
+		} else {
+			parts = append(parts, key.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// isDefault reports whether the query is the plain `ORDER BY id` listing that
+// the generated queries already cover
+func (q listQuery) isDefault() bool {
+	return len(q.Filters) == 0 && q.sortString() == "id"
+}
+
+// cursorFor returns the cursor pointing right after the given article
+func (q listQuery) cursorFor(a db.Article) *cursor {
+	values := make([]string, 0, len(q.Sort))
+	for _, key := range q.Sort {
+		values = append(values, articleFields[key.Field].value(a))
+	}
+	return &cursor{Sort: q.sortString(), Values: values}
+}
+
+// storeQuery turns the query and the page into the store query fetching one
+// article more than the page limit, so that the caller can tell whether
+// there is a next page
+func (q listQuery) storeQuery(page pageRequest) (store.ArticleQuery, error) {
+	sq := store.ArticleQuery{
+		Filters: q.Filters,
+		Sort:    q.Sort,
+		Limit:   page.Limit + 1,
+		Offset:  page.Offset,
+	}
+	if page.After == nil {
+		return sq, nil
+	}
+
+	cur := page.After
+	if cur.Sort != q.sortString() || len(cur.Values) != len(q.Sort) {
+		return store.ArticleQuery{}, ErrorInvalidCursor
+	}
+	sq.After = make([]any, len(q.Sort))
+	for i, key := range q.Sort {
+		v, err := articleFields[key.Field].parse(cur.Values[i])
+		if err != nil {
+			return store.ArticleQuery{}, ErrorInvalidCursor
+		}
+		sq.After[i] = v
+	}
+	return sq, nil
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Revision is a saved state of an article. The current state is revision
+// number article.version, earlier ones come from article_revisions.
+type Revision struct {
+	db.ArticleRevision
+	Current bool `json:"current"`
+}
+
+// FieldChange is the old and the new value of a changed single-line field
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// DiffLine is a line of a body diff, Op is "equal", "delete" or "insert"
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff compares two revisions of an article. Fields lists the
+// changed name, slug and status, the body is diffed line by line.
+type RevisionDiff struct {
+	From   int64                  `json:"from"`
+	To     int64                  `json:"to"`
+	Fields map[string]FieldChange `json:"fields"`
+	Body   []DiffLine             `json:"body"`
+}
+
+// saveRevision stores the state an article is about to leave
+func saveRevision(ctx context.Context, q store.ArticleStore, current db.Article) error {
+	return q.CreateRevision(ctx, db.CreateRevisionParams{
+		ArticleID: current.ID,
+		Rev:       current.Version,
+		Name:      current.Name,
+		Body:      current.Body,
+		Slug:      current.Slug,
+		Status:    current.Status,
+		CreatedAt: current.UpdatedAt,
+	})
+}
+
+func currentRevision(article db.Article) Revision {
+	return Revision{
+		ArticleRevision: db.ArticleRevision{
+			ArticleID: article.ID,
+			Rev:       article.Version,
+			Name:      article.Name,
+			Body:      article.Body,
+			Slug:      article.Slug,
+			Status:    article.Status,
+			CreatedAt: article.UpdatedAt,
+		},
+		Current: true,
+	}
+}
+
+// ListRevisions lists the revisions of an article newest first, starting
+// with its current state on the first page
+func (h *ArticleHandler) ListRevisions(c *gin.Context) {
+	id, err := h.parseID(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	if page.After != nil {
+		badRequest(c, &QueryError{Param: "after", Err: ErrorCursorNotSupported})
+		return
+	}
+
+	article, err := h.Store.GetArticle(c.Request.Context(), id)
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	// The current state takes the first slot of the first page
+	revisions := []Revision{}
+	offset, limit := page.Offset, page.Limit+1
+	if offset == 0 {
+		revisions = append(revisions, currentRevision(article))
+		limit--
+	} else {
+		offset--
+	}
+
+	saved, err := h.Store.ListRevisions(c.Request.Context(), db.ListRevisionsParams{
+		ArticleID: id,
+		Offset:    offset,
+		Limit:     limit,
+	})
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+	for _, r := range saved {
+		revisions = append(revisions, Revision{ArticleRevision: r})
+	}
+
+	hasNext := int64(len(revisions)) > page.Limit
+	if hasNext {
+		revisions = revisions[:page.Limit]
+	}
+
+	setLinkHeader(c, page, hasNext, nil)
+	c.JSON(http.StatusOK, Page[Revision]{Items: revisions})
+}
+
+func (h *ArticleHandler) GetRevision(c *gin.Context) {
+	id, rev, err := h.parseRevisionPath(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	article, err := h.Store.GetArticle(c.Request.Context(), id)
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	revision, err := loadRevision(c.Request.Context(), h.Store, article, rev)
+	if err != nil {
+		handleRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions compares revision :rev with the one given by ?to=, which
+// defaults to the current state
+func (h *ArticleHandler) DiffRevisions(c *gin.Context) {
+	id, from, err := h.parseRevisionPath(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	article, err := h.Store.GetArticle(c.Request.Context(), id)
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	to := article.Version
+	if value, ok := c.GetQuery("to"); ok {
+		to, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || to <= 0 {
+			badRequest(c, &QueryError{Param: "to", Err: ErrorInvalidRevision})
+			return
+		}
+	}
+
+	var revisions [2]Revision
+	for i, rev := range []int64{from, to} {
+		revisions[i], err = loadRevision(c.Request.Context(), h.Store, article, rev)
+		if err != nil {
+			handleRevisionError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, diffRevisions(revisions[0], revisions[1]))
+}
+
+// RevertRevision makes the content of an earlier revision current again. It
+// is an ordinary update, so the state it replaces becomes a revision too.
+func (h *ArticleHandler) RevertRevision(c *gin.Context) {
+	id, rev, err := h.parseRevisionPath(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	var article db.Article
+	err = h.Store.InTx(c.Request.Context(), func(q store.ArticleStore) error {
+		current, err := q.GetArticle(c.Request.Context(), id)
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(c, current); err != nil {
+			return err
+		}
+
+		revision, err := loadRevision(c.Request.Context(), q, current, rev)
+		if err != nil {
+			return err
+		}
+		if revision.Current {
+			article = current
+			return nil
+		}
+
+		article, err = updateArticle(c, q, current, ArticleParams{
+			Name:   revision.Name,
+			Body:   revision.Body,
+			Slug:   revision.Slug,
+			Status: revision.Status,
+		})
+		return err
+	})
+	if err != nil {
+		handleRevisionError(c, err)
+		return
+	}
+
+	writeArticle(c, http.StatusOK, article)
+}
+
+func (h *ArticleHandler) parseRevisionPath(c *gin.Context) (int64, int64, error) {
+	id, err := h.parseID(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rev, err := strconv.ParseInt(c.Param("rev"), 10, 64)
+	if err != nil || rev <= 0 {
+		return 0, 0, ErrorInvalidRevision
+	}
+	return id, rev, nil
+}
+
+// loadRevision returns revision rev of article, which is the article itself
+// for its current version
+func loadRevision(ctx context.Context, q store.ArticleStore, article db.Article, rev int64) (Revision, error) {
+	if rev == article.Version {
+		return currentRevision(article), nil
+	}
+
+	saved, err := q.GetRevision(ctx, db.GetRevisionParams{ArticleID: article.ID, Rev: rev})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Revision{}, ErrorRevisionNotFound
+	}
+	if err != nil {
+		return Revision{}, err
+	}
+	return Revision{ArticleRevision: saved}, nil
+}
+
+func handleRevisionError(c *gin.Context, err error) {
+	if errors.Is(err, ErrorRevisionNotFound) {
+		notFound(c, err)
+		return
+	}
+	handleDBError(c, err)
+}
+
+func diffRevisions(from, to Revision) RevisionDiff {
+	diff := RevisionDiff{
+		From:   from.Rev,
+		To:     to.Rev,
+		Fields: map[string]FieldChange{},
+		Body:   diffLines(from.Body, to.Body),
+	}
+
+	if from.Name != to.Name {
+		diff.Fields["name"] = FieldChange{From: from.Name, To: to.Name}
+	}
+	if !equalSlugs(from.Slug, to.Slug) {
+		diff.Fields["slug"] = FieldChange{From: from.Slug, To: to.Slug}
+	}
+	if from.Status != to.Status {
+		diff.Fields["status"] = FieldChange{From: from.Status, To: to.Status}
+	}
+
+	return diff
+}
+
+func equalSlugs(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// diffLines is a line-level diff of two texts. Replaced lines are reported
+// as deletions followed by insertions, like in a unified diff.
+func diffLines(from, to string) []DiffLine {
+	a, b := splitLines(from), splitLines(to)
+
+	lines := []DiffLine{}
+	add := func(op string, texts []string) {
+		for _, text := range texts {
+			lines = append(lines, DiffLine{Op: op, Text: text})
+		}
+	}
+
+	for _, op := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch op.Tag {
+		case 'e':
+			add("equal", a[op.I1:op.I2])
+		case 'd':
+			add("delete", a[op.I1:op.I2])
+		case 'i':
+			add("insert", b[op.J1:op.J2])
+		case 'r':
+			add("delete", a[op.I1:op.I2])
+			add("insert", b[op.J1:op.J2])
+		}
+	}
+	return lines
+}
+
+// splitLines treats an empty text as having no lines and ignores the final
+// newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -1,19 +1,59 @@
 package handlers
 
 import (
+	"cmp"
 	"database/sql"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
-	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/internal/metrics"
+	"github.com/hexlet-components/go-gin-example/store"
 )
 
-func SetupRouter(database *sql.DB) *gin.Engine {
-	queries := db.New(database)
-	h := NewArticleHandler(queries)
+// RouterConfig holds the settings of the API other than the article store
+type RouterConfig struct {
+	// DB is the database behind the store, GET /diagnostics reports on its
+	// schema. Without it, e.g. with the in-memory store, the schema is left
+	// out.
+	DB *sql.DB
+	// Dialect is what DB speaks, one of the migrate dialects, empty means
+	// SQLite
+	Dialect string
+	// AdminToken enables admin-only operations such as hard deletes
+	AdminToken string
+	// Migrations are the schema versions the binary has, GET /diagnostics
+	// reports which of them are not applied
+	Migrations []int64
+	// Logger receives the access log, nil means slog.Default()
+	Logger *slog.Logger
+	// Metrics collects the request metrics, nil disables them. The router
+	// only serves GET /metrics with MetricsRoute, otherwise another server
+	// is expected to.
+	Metrics      *metrics.Metrics
+	MetricsRoute bool
+}
+
+func SetupRouter(articles store.ArticleStore, cfg RouterConfig) *gin.Engine {
+	h := NewArticleHandler(articles)
+	h.AdminToken = cfg.AdminToken
+
+	r := gin.New()
+	r.Use(RequestLogger(cmp.Or(cfg.Logger, slog.Default())))
+	if cfg.Metrics != nil {
+		r.Use(RequestMetrics(cfg.Metrics))
+		if cfg.MetricsRoute {
+			r.GET("/metrics", gin.WrapH(cfg.Metrics.Handler()))
+		}
+	}
+	r.Use(recovery())
+	r.NoRoute(func(c *gin.Context) {
+		notFound(c, ErrorRouteNotFound)
+	})
+
+	h.Register(r.Group("/articles"))
 
-	r := gin.Default()
-	articles := r.Group("/articles")
-	h.Register(articles)
+	diagnostics := &DiagnosticsHandler{DB: cfg.DB, Dialect: cfg.Dialect, Migrations: cfg.Migrations}
+	r.GET("/diagnostics", diagnostics.Get)
 
 	return r
 }
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hexlet-components/go-gin-example/store"
+)
+
+// SearchResult is an article matched by full-text search
+type SearchResult = store.SearchResult
+
+func (h *ArticleHandler) Search(c *gin.Context) {
+	q := c.Query("q")
+	if strings.TrimSpace(q) == "" {
+		badRequest(c, &QueryError{Param: "q", Err: ErrorSearchQueryEmpty})
+		return
+	}
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	if page.After != nil {
+		badRequest(c, &QueryError{Param: "after", Err: ErrorCursorNotSupported})
+		return
+	}
+
+	results, err := h.Store.SearchArticles(c.Request.Context(), q, page.Limit+1, page.Offset)
+	if errors.Is(err, ErrorSearchUnavailable) {
+		notImplemented(c, err)
+		return
+	}
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+	if results == nil {
+		results = []SearchResult{}
+	}
+
+	hasNext := int64(len(results)) > page.Limit
+	if hasNext {
+		results = results[:page.Limit]
+	}
+
+	setLinkHeader(c, page, hasNext, nil)
+	c.JSON(http.StatusOK, Page[SearchResult]{Items: results})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/hexlet-components/go-gin-example/store"
+)
+
+// GetBySlug serves an article by its current slug and redirects former slugs
+// to the current one, so links shared before a rename keep working
+func (h *ArticleHandler) GetBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	article, err := h.Store.GetArticleBySlug(c.Request.Context(), slug)
+	if err == nil {
+		writeArticle(c, http.StatusOK, article)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		handleDBError(c, err)
+		return
+	}
+
+	current, err := h.Store.GetCurrentSlug(c.Request.Context(), slug)
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+	if current == nil {
+		notFound(c, ErrorArticleNotFound)
+		return
+	}
+
+	location := strings.TrimSuffix(c.Request.URL.Path, slug) + *current
+	if query := c.Request.URL.RawQuery; query != "" {
+		location += "?" + query
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+}
+
+// articleSlug picks the slug an article gets when it is saved with input.
+// current is nil for new articles.
+//
+// An explicit slug is used as is and must not belong to another article, now
+// or in the past. Otherwise the slug is generated from the name, with a -2,
+// -3, ... suffix on collision. Edits that keep the name keep the slug, even
+// if it has a suffix that is no longer needed.
+func articleSlug(ctx context.Context, q store.ArticleStore, current *db.Article, input ArticleParams) (string, error) {
+	var id int64
+	if current != nil {
+		id = current.ID
+	}
+
+	if input.Slug != nil {
+		taken, err := q.ListTakenSlugs(ctx, db.ListTakenSlugsParams{Base: *input.Slug, ArticleID: id})
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(taken, *input.Slug) {
+			return "", &FieldError{Field: "slug", Err: ErrorArticleExists}
+		}
+		return *input.Slug, nil
+	}
+
+	base := textnorm.Slugify(input.Name)
+	if current != nil && current.Slug != nil && textnorm.Slugify(current.Name) == base {
+		return *current.Slug, nil
+	}
+
+	taken, err := q.ListTakenSlugs(ctx, db.ListTakenSlugsParams{Base: base, ArticleID: id})
+	if err != nil {
+		return "", err
+	}
+	return nextFreeSlug(base, taken), nil
+}
+
+func nextFreeSlug(base string, taken []string) string {
+	if !slices.Contains(taken, base) {
+		return base
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", base, n)
+		if !slices.Contains(taken, candidate) {
+			return candidate
+		}
+	}
+}
+
+// saveSlugHistory remembers the slug an article is moving away from, so
+// GetBySlug can redirect it
+func saveSlugHistory(ctx context.Context, q store.ArticleStore, current db.Article, slug string, now time.Time) error {
+	if current.Slug != nil && *current.Slug != slug {
+		err := q.AddSlugHistory(ctx, db.AddSlugHistoryParams{
+			Slug:      *current.Slug,
+			ArticleID: current.ID,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// The article may be taking back one of its former slugs
+	return q.DeleteSlugHistory(ctx, slug)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/store"
+)
+
+// Trash lists deleted articles that have not been purged yet, most recently
+// deleted first. Like search it only pages with limit and offset.
+func (h *ArticleHandler) Trash(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	if page.After != nil {
+		badRequest(c, &QueryError{Param: "after", Err: ErrorCursorNotSupported})
+		return
+	}
+
+	articles, err := h.Store.ListDeletedArticles(c.Request.Context(), db.ListDeletedArticlesParams{
+		Offset: page.Offset,
+		Limit:  page.Limit + 1,
+	})
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	hasNext := int64(len(articles)) > page.Limit
+	if hasNext {
+		articles = articles[:page.Limit]
+	}
+
+	setLinkHeader(c, page, hasNext, nil)
+	c.JSON(http.StatusOK, Page[db.Article]{Items: append([]db.Article{}, articles...)})
+}
+
+// Restore takes an article out of the trash. An article that was never
+// deleted is a conflict rather than missing.
+func (h *ArticleHandler) Restore(c *gin.Context) {
+	id, err := h.parseID(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	article, err := h.Store.RestoreArticle(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := h.Store.GetArticle(c.Request.Context(), id); getErr == nil {
+			conflict(c, ErrorArticleNotInTrash)
+			return
+		}
+	}
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	writeArticle(c, http.StatusOK, article)
+}
+
+// parseHard reads the ?hard= flag of DELETE, which only admins may set
+func (h *ArticleHandler) parseHard(c *gin.Context) (bool, error) {
+	value, ok := c.GetQuery("hard")
+	if !ok {
+		return false, nil
+	}
+
+	hard, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &QueryError{Param: "hard", Err: ErrorInvalidBoolean}
+	}
+	if hard && !h.isAdmin(c) {
+		return false, ErrorAdminRequired
+	}
+	return hard, nil
+}
+
+// isAdmin checks the bearer token against AdminToken. Without a configured
+// token nobody is an admin.
+func (h *ArticleHandler) isAdmin(c *gin.Context) bool {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || h.AdminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.AdminToken)) == 1
+}
+
+// removeArticle moves an article to the trash, or deletes it for good when
+// hard is set. A valid version makes the removal conditional on it.
+func removeArticle(c *gin.Context, q store.ArticleStore, id int64, hard bool, version sql.NullInt64) (int64, error) {
+	if hard {
+		return q.DeleteArticle(c.Request.Context(), db.DeleteArticleParams{ID: id, Version: version})
+	}
+
+	now := time.Now().UTC()
+	return q.SoftDeleteArticle(c.Request.Context(), db.SoftDeleteArticleParams{ID: id, Version: version, Now: &now})
+}
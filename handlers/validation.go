@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Report fields by their JSON names, which is what clients send
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return slugPattern.MatchString(fl.Field().String())
+	})
+}
+
+// FieldViolation describes one failed validation rule of a request body field
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError is a request body that failed binding validation, with
+// messages already translated to the language the client asked for
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrorValidationFailed
+}
+
+// supportedLanguages lists the message catalogs, the first one is the default
+var supportedLanguages = []language.Tag{language.English, language.Russian}
+
+var languageMatcher = language.NewMatcher(supportedLanguages)
+
+type messageCatalog struct {
+	// rules holds messages for rules that read the same for every field kind,
+	// lengths and sizes differ between strings and numbers
+	rules    map[string]string
+	length   map[string]string
+	size     map[string]string
+	fallback string
+}
+
+var catalogs = map[language.Tag]messageCatalog{
+	language.English: {
+		rules: map[string]string{
+			"required": "is required",
+			"oneof":    "must be one of: {param}",
+			"email":    "must be a valid email address",
+			"url":      "must be a valid URL",
+			"slug":     "must contain only lowercase latin letters and digits separated by single hyphens",
+		},
+		length: map[string]string{
+			"min": "must be at least {param} characters long",
+			"max": "must be at most {param} characters long",
+			"len": "must be exactly {param} characters long",
+		},
+		size: map[string]string{
+			"min": "must be at least {param}",
+			"max": "must be at most {param}",
+			"gt":  "must be greater than {param}",
+			"gte": "must be greater than or equal to {param}",
+			"lt":  "must be less than {param}",
+			"lte": "must be less than or equal to {param}",
+		},
+		fallback: "is invalid ({rule})",
+	},
+	language.Russian: {
+		rules: map[string]string{
+			"required": "обязательное поле",
+			"oneof":    "должно быть одним из: {param}",
+			"email":    "должно быть корректным адресом электронной почты",
+			"url":      "должно быть корректным URL",
+			"slug":     "может содержать только строчные латинские буквы и цифры, разделённые одиночными дефисами",
+		},
+		length: map[string]string{
+			"min": "должно содержать не меньше {param} символов",
+			"max": "должно содержать не больше {param} символов",
+			"len": "должно содержать ровно {param} символов",
+		},
+		size: map[string]string{
+			"min": "должно быть не меньше {param}",
+			"max": "должно быть не больше {param}",
+			"gt":  "должно быть больше {param}",
+			"gte": "должно быть больше или равно {param}",
+			"lt":  "должно быть меньше {param}",
+			"lte": "должно быть меньше или равно {param}",
+		},
+		fallback: "не прошло проверку {rule}",
+	},
+}
+
+// requestLanguage picks a message catalog from the Accept-Language header
+func requestLanguage(c *gin.Context) language.Tag {
+	tags, _, _ := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	_, index, _ := languageMatcher.Match(tags...)
+	return supportedLanguages[index]
+}
+
+func (m messageCatalog) message(fe validator.FieldError) string {
+	tmpl, ok := m.rules[fe.Tag()]
+	if !ok {
+		switch fe.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			tmpl, ok = m.length[fe.Tag()]
+		default:
+			tmpl, ok = m.size[fe.Tag()]
+		}
+	}
+	if !ok {
+		tmpl = m.fallback
+	}
+
+	return strings.NewReplacer("{param}", fe.Param(), "{rule}", fe.Tag()).Replace(tmpl)
+}
+
+// newValidationError translates validator errors for the current request.
+// Field paths drop the root struct name, so "ArticleParams.name" is "name".
+func newValidationError(c *gin.Context, errs validator.ValidationErrors) *ValidationError {
+	lang := requestLanguage(c)
+	catalog := catalogs[lang]
+	c.Header("Content-Language", lang.String())
+
+	violations := make([]FieldViolation, 0, len(errs))
+	for _, fe := range errs {
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		violations = append(violations, FieldViolation{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: catalog.message(fe),
+		})
+	}
+
+	return &ValidationError{Violations: violations}
+}
+
+// translateBindingError turns validator failures into a ValidationError and
+// leaves every other binding error (malformed JSON, empty body) as it is
+func translateBindingError(c *gin.Context, err error) error {
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return newValidationError(c, errs)
+	}
+	return err
+}
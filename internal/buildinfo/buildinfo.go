@@ -0,0 +1,39 @@
+// Package buildinfo describes the running binary
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is set at build time with
+// -ldflags "-X github.com/hexlet-components/go-gin-example/internal/buildinfo.Version=v1.2.3"
+var Version = "dev"
+
+// Info is what the binary knows about how it was built
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// Read collects the build info. The commit comes from the VCS stamp the go
+// command embeds when building inside a git checkout.
+func Read() Info {
+	info := Info{Version: Version, Commit: "unknown", GoVersion: runtime.Version()}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
@@ -0,0 +1,51 @@
+// Package logging builds the slog logger the commands install as the default
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Formats of the log records
+const (
+	// FormatText is key=value lines, easy to read in a terminal
+	FormatText = "text"
+	// FormatJSON is one JSON object per line, for log collectors
+	FormatJSON = "json"
+)
+
+// Options choose what gets logged and how
+type Options struct {
+	// Level is the least severe level logged: debug, info, warn or error
+	Level string
+	// Format is FormatText or FormatJSON
+	Format string
+}
+
+// ParseLevel parses debug, info, warn or error, case insensitively
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level: %s, use debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// New returns a logger writing to w
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch opts.Format {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format: %s, use %s or %s", opts.Format, FormatText, FormatJSON)
+	}
+}
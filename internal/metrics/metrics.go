@@ -0,0 +1,119 @@
+// Package metrics collects the Prometheus metrics of the API
+package metrics
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/internal/buildinfo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// methods are the request methods recorded under their own name
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// Metrics is a registry of its own rather than the global one, so that
+// every server, tests included, starts from zero
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+	queries  *prometheus.HistogramVec
+}
+
+// New registers the HTTP, query and build metrics together with the Go
+// runtime and process ones
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests served, by route template and status class",
+		}, []string{"method", "route", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time to serve HTTP requests, by route template and status class",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests being served",
+		}),
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "db_query_duration_seconds",
+			Help: "Time to run database queries, by query name",
+			// Queries take far less than requests
+			Buckets: []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"query"}),
+	}
+
+	info := buildinfo.Read()
+	build := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "go_gin_example_build_info",
+		Help: "Always 1, the labels describe the running binary",
+		ConstLabels: prometheus.Labels{
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"modified":   strconv.FormatBool(info.Modified),
+			"go_version": info.GoVersion,
+		},
+	})
+	build.Set(1)
+
+	m.registry.MustRegister(
+		m.requests, m.latency, m.inFlight, m.queries, build,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterPools adds the sql.DBStats of the pools, labelled with db_name
+// writer and reader, or main when both are the same pool
+func (m *Metrics) RegisterPools(pools *db.Pools) {
+	if pools.Writer == pools.Reader {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(pools.Writer, "main"))
+		return
+	}
+	m.registry.MustRegister(
+		collectors.NewDBStatsCollector(pools.Writer, "writer"),
+		collectors.NewDBStatsCollector(pools.Reader, "reader"),
+	)
+}
+
+// StartRequest counts a request in flight, the returned function records it
+// once it is served. Route is the template the request matched, e.g.
+// /articles/:id, and methods outside of RFC 9110 and PATCH are recorded as
+// other, so that the number of series stays bounded.
+func (m *Metrics) StartRequest() func(method, route string, status int) {
+	start := time.Now()
+	m.inFlight.Inc()
+	return func(method, route string, status int) {
+		m.inFlight.Dec()
+		if !slices.Contains(methods, method) {
+			method = "other"
+		}
+		class := strconv.Itoa(status/100) + "xx"
+		m.requests.WithLabelValues(method, route, class).Inc()
+		m.latency.WithLabelValues(method, route, class).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveQuery records a query, it is a store.QueryObserver
+func (m *Metrics) ObserveQuery(name string, elapsed time.Duration) {
+	m.queries.WithLabelValues(name).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,110 @@
+// Package server runs the HTTP server with timeouts and graceful shutdown
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Options are the limits of the server. The zero value of a timeout means
+// no timeout, as in http.Server.
+type Options struct {
+	// ReadHeaderTimeout bounds reading the request headers, it is what
+	// protects against clients that open connections and go silent
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout bounds reading the whole request, body included
+	ReadTimeout time.Duration
+	// WriteTimeout bounds the time from the end of reading the headers to
+	// the end of writing the response
+	WriteTimeout time.Duration
+	// IdleTimeout is how long a keep-alive connection waits for the next
+	// request
+	IdleTimeout time.Duration
+	// MaxHeaderBytes limits the size of the request headers, larger ones get
+	// 431 Request Header Fields Too Large
+	MaxHeaderBytes int
+	// ShutdownTimeout is how long in-flight requests get to finish once
+	// shutdown starts, the remaining connections are closed after it
+	ShutdownTimeout time.Duration
+}
+
+// DefaultOptions are the limits the CLI starts with
+func DefaultOptions() Options {
+	return Options{
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+		ShutdownTimeout:   15 * time.Second,
+	}
+}
+
+// Validate rejects negative limits and a zero shutdown timeout, which would
+// cut off every in-flight request
+func (o Options) Validate() error {
+	for name, d := range map[string]time.Duration{
+		"read header timeout": o.ReadHeaderTimeout,
+		"read timeout":        o.ReadTimeout,
+		"write timeout":       o.WriteTimeout,
+		"idle timeout":        o.IdleTimeout,
+		"shutdown timeout":    o.ShutdownTimeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s cannot be negative: %s", name, d)
+		}
+	}
+	if o.ShutdownTimeout == 0 {
+		return errors.New("shutdown timeout must be positive")
+	}
+	if o.MaxHeaderBytes <= 0 {
+		return fmt.Errorf("max header bytes must be positive: %d", o.MaxHeaderBytes)
+	}
+	return nil
+}
+
+// Serve serves handler on ln until ctx is done, then stops accepting
+// connections and waits up to ShutdownTimeout for in-flight requests. It
+// returns once the server has stopped, an error means it failed or did not
+// drain in time.
+func Serve(ctx context.Context, ln net.Listener, handler http.Handler, opts Options) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	addr := slog.String("addr", ln.Addr().String())
+	slog.Info("Shutting down, waiting for in-flight requests", addr, slog.Duration("timeout", opts.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("in-flight requests did not finish in %s, connections closed: %w", opts.ShutdownTimeout, err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("Server stopped, all requests finished", addr)
+	return nil
+}
@@ -0,0 +1,103 @@
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// DefaultSlug is used when a name has nothing that survives slugification
+	DefaultSlug = "article"
+	// MaxSlugLength leaves room for collision suffixes within the 255 limit
+	MaxSlugLength = 100
+)
+
+// gost maps Cyrillic letters to Latin following GOST 7.79-2000 system B, with
+// the apostrophes and backticks it uses for ъ, ь, ы and э dropped since they
+// are not allowed in slugs. ц is handled separately, it depends on the next
+// letter.
+var gost = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "x", 'ч': "ch", 'ш': "sh", 'щ': "shh", 'ъ': "", 'ы': "y",
+	'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	// Ukrainian and Belarusian letters from the same standard
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// Slugify turns an article name into a URL slug: lowercase Latin letters and
+// digits separated by single hyphens. Cyrillic is transliterated, Latin
+// diacritics are stripped and everything else becomes a separator.
+func Slugify(name string) string {
+	runes := []rune(strings.ToLower(norm.NFKC.String(name)))
+
+	var sb strings.Builder
+	pendingHyphen := false
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if pendingHyphen && sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		pendingHyphen = false
+		sb.WriteString(s)
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == 'ц':
+			// "c" before е, и, ы, й and "cz" everywhere else
+			if i+1 < len(runes) && strings.ContainsRune("еиый", runes[i+1]) {
+				write("c")
+			} else {
+				write("cz")
+			}
+		case gost[r] != "" || isSilentSign(r):
+			write(gost[r])
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			write(string(r))
+		case unicode.IsLetter(r):
+			write(stripDiacritics(r))
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return truncateSlug(sb.String())
+}
+
+func isSilentSign(r rune) bool {
+	return r == 'ъ' || r == 'ь'
+}
+
+// stripDiacritics keeps the ASCII base letters of a decomposed rune, so "é"
+// becomes "e" and letters with no ASCII base are dropped
+func stripDiacritics(r rune) string {
+	var sb strings.Builder
+	for _, d := range norm.NFKD.String(string(r)) {
+		if d < unicode.MaxASCII && unicode.IsLetter(d) {
+			sb.WriteRune(unicode.ToLower(d))
+		}
+	}
+	return sb.String()
+}
+
+// truncateSlug cuts the slug to MaxSlugLength on a word boundary when there
+// is one
+func truncateSlug(slug string) string {
+	if len(slug) > MaxSlugLength {
+		slug = slug[:MaxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+		slug = strings.Trim(slug, "-")
+	}
+	if slug == "" {
+		return DefaultSlug
+	}
+	return slug
+}
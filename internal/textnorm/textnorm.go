@@ -0,0 +1,20 @@
+// Package textnorm holds the text normalization rules shared by the handlers
+// and the data migrations, so both always produce identical keys.
+package textnorm
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NameKey returns the form of an article name used for uniqueness checks:
+// NFKC-normalized and case-folded (NFKC_Casefold), with runs of whitespace
+// collapsed. "Статья", "СТАТЬЯ" and "Ｓтатья" all get the same key.
+func NameKey(name string) string {
+	s := norm.NFKC.String(name)
+	s = cases.Fold().String(s)
+	s = norm.NFKC.String(s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// UniqueError is the unique violation of the in-memory store
+type UniqueError struct {
+	// Field is the article field whose value is taken
+	Field string
+}
+
+func (e *UniqueError) Error() string {
+	return fmt.Sprintf("unique constraint failed: %s", e.Field)
+}
+
+// uniqueColumns maps the SQLite columns behind unique indexes to the
+// article fields they are derived from
+var uniqueColumns = map[string]string{
+	"articles.name_key": "name",
+	"articles.slug":     "slug",
+}
+
+// uniqueIndexes does the same for the PostgreSQL unique indexes
+var uniqueIndexes = map[string]string{
+	"articles_name_key_idx": "name",
+	"articles_slug_idx":     "slug",
+}
+
+// pgUniqueViolation is the SQLSTATE of unique_violation
+const pgUniqueViolation = "23505"
+
+// UniqueViolation returns the article field behind the unique index err
+// reports, ok is false when err is not a unique violation. Indexes that are
+// not about an article field give the column the database names, if any.
+func UniqueViolation(err error) (field string, ok bool) {
+	var uniqueErr *UniqueError
+	if errors.As(err, &uniqueErr) {
+		return uniqueErr.Field, true
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey) {
+		return sqliteUniqueField(sqliteErr), true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return uniqueIndexes[pgErr.ConstraintName], true
+	}
+	return "", false
+}
+
+// IsUniqueViolation tells whether err is a unique violation in any of the
+// stores
+func IsUniqueViolation(err error) bool {
+	_, ok := UniqueViolation(err)
+	return ok
+}
+
+// sqliteUniqueField extracts the field from a "UNIQUE constraint failed:
+// table.column" message
+func sqliteUniqueField(err sqlite3.Error) string {
+	_, columns, _ := strings.Cut(err.Error(), "UNIQUE constraint failed: ")
+	column, _, _ := strings.Cut(columns, ",")
+	if field, ok := uniqueColumns[column]; ok {
+		return field
+	}
+	_, field, _ := strings.Cut(column, ".")
+	return field
+}
@@ -0,0 +1,603 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+)
+
+// memoryData is everything the in-memory store holds. The articles and
+// revisions are replaced on every write and never changed in place, so a
+// shallow copy is a snapshot.
+type memoryData struct {
+	articles  map[int64]db.Article
+	slugs     map[string]db.ArticleSlug
+	revisions []db.ArticleRevision
+	lastID    int64
+}
+
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		articles:  maps.Clone(d.articles),
+		slugs:     maps.Clone(d.slugs),
+		revisions: slices.Clone(d.revisions),
+		lastID:    d.lastID,
+	}
+}
+
+// memoryStore keeps everything in maps behind a mutex. A transaction holds
+// the mutex until it ends and restores a snapshot on rollback, so
+// transactions are serialized.
+type memoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+	inTx bool
+}
+
+// NewMemory returns an empty in-memory store. It behaves like the SQL
+// stores, except that Contains and Prefix filters on fields other than name
+// fold case beyond ASCII and search ranks matches by how often they occur.
+func NewMemory() ArticleStore {
+	return &memoryStore{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			articles: map[int64]db.Article{},
+			slugs:    map[string]db.ArticleSlug{},
+		},
+	}
+}
+
+// lock takes the mutex unless the store is bound to a transaction, which
+// holds it already
+func (s *memoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *memoryStore) InTx(ctx context.Context, fn func(ArticleStore) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	if err := fn(&memoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = *snapshot
+		return err
+	}
+	return nil
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// page applies LIMIT and OFFSET
+func page[T any](items []T, limit, offset int64) []T {
+	if offset >= int64(len(items)) {
+		return nil
+	}
+	items = items[offset:]
+	if limit < int64(len(items)) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// sorted returns the articles that pass keep, ordered by id
+func (s *memoryStore) sorted(keep func(db.Article) bool) []db.Article {
+	var articles []db.Article
+	for _, a := range s.data.articles {
+		if keep(a) {
+			articles = append(articles, a)
+		}
+	}
+	slices.SortFunc(articles, func(a, b db.Article) int { return cmp.Compare(a.ID, b.ID) })
+	return articles
+}
+
+func live(a db.Article) bool {
+	return a.DeletedAt == nil
+}
+
+// checkUnique enforces the unique indexes on name_key and slug for an
+// article with id
+func (s *memoryStore) checkUnique(id int64, nameKey string, slug *string) error {
+	for _, a := range s.data.articles {
+		if a.ID == id {
+			continue
+		}
+		if a.NameKey == nameKey {
+			return &UniqueError{Field: "name"}
+		}
+		if slug != nil && a.Slug != nil && *a.Slug == *slug {
+			return &UniqueError{Field: "slug"}
+		}
+	}
+	return nil
+}
+
+// remove deletes an article with its slug history and revisions, as the
+// foreign keys cascade
+func (s *memoryStore) remove(id int64) {
+	delete(s.data.articles, id)
+	maps.DeleteFunc(s.data.slugs, func(_ string, h db.ArticleSlug) bool { return h.ArticleID == id })
+	s.data.revisions = slices.DeleteFunc(s.data.revisions, func(r db.ArticleRevision) bool { return r.ArticleID == id })
+}
+
+// publishedAt follows the rule of the update queries: set the first time an
+// article becomes published, cleared when it goes back to draft
+func publishedAt(current *time.Time, status string, now time.Time) *time.Time {
+	switch {
+	case status == "published" && current == nil:
+		return &now
+	case status == "draft":
+		return nil
+	default:
+		return current
+	}
+}
+
+func (s *memoryStore) CreateArticle(ctx context.Context, arg db.CreateArticleParams) (db.Article, error) {
+	defer s.lock()()
+
+	if err := s.checkUnique(0, arg.NameKey, arg.Slug); err != nil {
+		return db.Article{}, err
+	}
+	s.data.lastID++
+	a := db.Article{
+		ID:          s.data.lastID,
+		Name:        arg.Name,
+		NameKey:     arg.NameKey,
+		Body:        arg.Body,
+		Slug:        clonePtr(arg.Slug),
+		Status:      arg.Status,
+		CreatedAt:   arg.Now,
+		UpdatedAt:   arg.Now,
+		PublishedAt: publishedAt(nil, arg.Status, arg.Now),
+		Version:     1,
+	}
+	s.data.articles[a.ID] = a
+	return a, nil
+}
+
+func (s *memoryStore) GetArticle(ctx context.Context, id int64) (db.Article, error) {
+	defer s.lock()()
+
+	a, ok := s.data.articles[id]
+	if !ok || !live(a) {
+		return db.Article{}, sql.ErrNoRows
+	}
+	return a, nil
+}
+
+func (s *memoryStore) GetArticleWithDeleted(ctx context.Context, id int64) (db.Article, error) {
+	defer s.lock()()
+
+	a, ok := s.data.articles[id]
+	if !ok {
+		return db.Article{}, sql.ErrNoRows
+	}
+	return a, nil
+}
+
+func (s *memoryStore) GetArticleBySlug(ctx context.Context, slug string) (db.Article, error) {
+	defer s.lock()()
+
+	for _, a := range s.data.articles {
+		if a.Slug != nil && *a.Slug == slug && live(a) {
+			return a, nil
+		}
+	}
+	return db.Article{}, sql.ErrNoRows
+}
+
+func (s *memoryStore) ListArticles(ctx context.Context) ([]db.Article, error) {
+	defer s.lock()()
+
+	return s.sorted(live), nil
+}
+
+func (s *memoryStore) ListArticlesPage(ctx context.Context, arg db.ListArticlesPageParams) ([]db.Article, error) {
+	defer s.lock()()
+
+	return page(s.sorted(live), arg.Limit, arg.Offset), nil
+}
+
+func (s *memoryStore) ListArticlesAfter(ctx context.Context, arg db.ListArticlesAfterParams) ([]db.Article, error) {
+	defer s.lock()()
+
+	articles := s.sorted(func(a db.Article) bool { return live(a) && a.ID > arg.AfterID })
+	return page(articles, arg.Limit, 0), nil
+}
+
+func (s *memoryStore) ListDeletedArticles(ctx context.Context, arg db.ListDeletedArticlesParams) ([]db.Article, error) {
+	defer s.lock()()
+
+	articles := s.sorted(func(a db.Article) bool { return !live(a) })
+	slices.SortStableFunc(articles, func(a, b db.Article) int {
+		return cmp.Or(b.DeletedAt.Compare(*a.DeletedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return page(articles, arg.Limit, arg.Offset), nil
+}
+
+func (s *memoryStore) UpdateArticle(ctx context.Context, arg db.UpdateArticleParams) (db.Article, error) {
+	defer s.lock()()
+
+	a, ok := s.data.articles[arg.ID]
+	if !ok || a.Version != arg.Version {
+		return db.Article{}, sql.ErrNoRows
+	}
+	if err := s.checkUnique(a.ID, arg.NameKey, arg.Slug); err != nil {
+		return db.Article{}, err
+	}
+	a.Name = arg.Name
+	a.NameKey = arg.NameKey
+	a.Body = arg.Body
+	a.Slug = clonePtr(arg.Slug)
+	a.Status = arg.Status
+	a.UpdatedAt = arg.Now
+	a.PublishedAt = publishedAt(a.PublishedAt, arg.Status, arg.Now)
+	a.Version++
+	s.data.articles[a.ID] = a
+	return a, nil
+}
+
+func (s *memoryStore) PatchArticle(ctx context.Context, arg db.PatchArticleParams) (db.Article, error) {
+	defer s.lock()()
+
+	a, ok := s.data.articles[arg.ID]
+	if !ok || a.Version != arg.Version {
+		return db.Article{}, sql.ErrNoRows
+	}
+	nameKey := a.NameKey
+	if arg.NameKey.Valid {
+		nameKey = arg.NameKey.String
+	}
+	slug := a.Slug
+	if arg.Slug != nil {
+		slug = clonePtr(arg.Slug)
+	}
+	if err := s.checkUnique(a.ID, nameKey, slug); err != nil {
+		return db.Article{}, err
+	}
+	if arg.Name.Valid {
+		a.Name = arg.Name.String
+	}
+	if arg.Body.Valid {
+		a.Body = arg.Body.String
+	}
+	if arg.Status.Valid {
+		a.Status = arg.Status.String
+		a.PublishedAt = publishedAt(a.PublishedAt, arg.Status.String, arg.Now)
+	}
+	a.NameKey = nameKey
+	a.Slug = slug
+	a.UpdatedAt = arg.Now
+	a.Version++
+	s.data.articles[a.ID] = a
+	return a, nil
+}
+
+func (s *memoryStore) SoftDeleteArticle(ctx context.Context, arg db.SoftDeleteArticleParams) (int64, error) {
+	defer s.lock()()
+
+	a, ok := s.data.articles[arg.ID]
+	if !ok || !live(a) || (arg.Version.Valid && a.Version != arg.Version.Int64) {
+		return 0, nil
+	}
+	a.DeletedAt = clonePtr(arg.Now)
+	a.Version++
+	s.data.articles[a.ID] = a
+	return 1, nil
+}
+
+func (s *memoryStore) RestoreArticle(ctx context.Context, id int64) (db.Article, error) {
+	defer s.lock()()
+
+	a, ok := s.data.articles[id]
+	if !ok || live(a) {
+		return db.Article{}, sql.ErrNoRows
+	}
+	a.DeletedAt = nil
+	a.Version++
+	s.data.articles[a.ID] = a
+	return a, nil
+}
+
+func (s *memoryStore) DeleteArticle(ctx context.Context, arg db.DeleteArticleParams) (int64, error) {
+	defer s.lock()()
+
+	a, ok := s.data.articles[arg.ID]
+	if !ok || (arg.Version.Valid && a.Version != arg.Version.Int64) {
+		return 0, nil
+	}
+	s.remove(a.ID)
+	return 1, nil
+}
+
+func (s *memoryStore) PurgeDeletedArticles(ctx context.Context, before *time.Time) (int64, error) {
+	defer s.lock()()
+
+	if before == nil {
+		return 0, nil
+	}
+	var purged int64
+	for _, a := range s.data.articles {
+		if a.DeletedAt != nil && a.DeletedAt.Before(*before) {
+			s.remove(a.ID)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (s *memoryStore) ListTakenSlugs(ctx context.Context, arg db.ListTakenSlugsParams) ([]string, error) {
+	defer s.lock()()
+
+	derived := func(slug string) bool {
+		return slug == arg.Base || strings.HasPrefix(slug, arg.Base+"-")
+	}
+	var taken []string
+	for _, a := range s.data.articles {
+		if a.ID != arg.ArticleID && a.Slug != nil && derived(*a.Slug) {
+			taken = append(taken, *a.Slug)
+		}
+	}
+	for _, h := range s.data.slugs {
+		if h.ArticleID != arg.ArticleID && derived(h.Slug) {
+			taken = append(taken, h.Slug)
+		}
+	}
+	slices.Sort(taken)
+	return slices.Compact(taken), nil
+}
+
+func (s *memoryStore) GetCurrentSlug(ctx context.Context, slug string) (*string, error) {
+	defer s.lock()()
+
+	h, ok := s.data.slugs[slug]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	a, ok := s.data.articles[h.ArticleID]
+	if !ok || !live(a) {
+		return nil, sql.ErrNoRows
+	}
+	return clonePtr(a.Slug), nil
+}
+
+func (s *memoryStore) AddSlugHistory(ctx context.Context, arg db.AddSlugHistoryParams) error {
+	defer s.lock()()
+
+	s.data.slugs[arg.Slug] = db.ArticleSlug(arg)
+	return nil
+}
+
+func (s *memoryStore) DeleteSlugHistory(ctx context.Context, slug string) error {
+	defer s.lock()()
+
+	delete(s.data.slugs, slug)
+	return nil
+}
+
+func (s *memoryStore) CreateRevision(ctx context.Context, arg db.CreateRevisionParams) error {
+	defer s.lock()()
+
+	for _, r := range s.data.revisions {
+		if r.ArticleID == arg.ArticleID && r.Rev == arg.Rev {
+			return &UniqueError{Field: "rev"}
+		}
+	}
+	r := db.ArticleRevision(arg)
+	r.Slug = clonePtr(arg.Slug)
+	s.data.revisions = append(s.data.revisions, r)
+	return nil
+}
+
+func (s *memoryStore) ListRevisions(ctx context.Context, arg db.ListRevisionsParams) ([]db.ArticleRevision, error) {
+	defer s.lock()()
+
+	var revisions []db.ArticleRevision
+	for _, r := range s.data.revisions {
+		if r.ArticleID == arg.ArticleID {
+			revisions = append(revisions, r)
+		}
+	}
+	slices.SortFunc(revisions, func(a, b db.ArticleRevision) int { return cmp.Compare(b.Rev, a.Rev) })
+	return page(revisions, arg.Limit, arg.Offset), nil
+}
+
+func (s *memoryStore) GetRevision(ctx context.Context, arg db.GetRevisionParams) (db.ArticleRevision, error) {
+	defer s.lock()()
+
+	for _, r := range s.data.revisions {
+		if r.ArticleID == arg.ArticleID && r.Rev == arg.Rev {
+			return r, nil
+		}
+	}
+	return db.ArticleRevision{}, sql.ErrNoRows
+}
+
+func (s *memoryStore) FindArticles(ctx context.Context, q ArticleQuery) ([]db.Article, error) {
+	// The SQL stores reject the same queries
+	if _, _, err := articleQuerySQL(q); err != nil {
+		return nil, err
+	}
+
+	defer s.lock()()
+
+	articles := s.sorted(func(a db.Article) bool {
+		if !live(a) {
+			return false
+		}
+		for _, f := range q.Filters {
+			if !matches(a, f) {
+				return false
+			}
+		}
+		return q.After == nil || comesAfter(a, q.Sort, q.After)
+	})
+	slices.SortStableFunc(articles, func(a, b db.Article) int {
+		for _, key := range q.Sort {
+			c := compareValues(fieldValue(a, key.Field), fieldValue(b, key.Field))
+			if key.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	offset := q.Offset
+	if q.After != nil {
+		offset = 0
+	}
+	return page(articles, q.Limit, offset), nil
+}
+
+// fieldValue returns the value of an ArticleFields field, nil for NULL
+func fieldValue(a db.Article, field string) any {
+	switch field {
+	case "id":
+		return a.ID
+	case "name":
+		return a.Name
+	case "body":
+		return a.Body
+	case "slug":
+		if a.Slug == nil {
+			return nil
+		}
+		return *a.Slug
+	case "status":
+		return a.Status
+	case "created_at":
+		return a.CreatedAt
+	case "updated_at":
+		return a.UpdatedAt
+	case "published_at":
+		if a.PublishedAt == nil {
+			return nil
+		}
+		return *a.PublishedAt
+	}
+	return nil
+}
+
+// compareValues orders two field values, NULL first like SQLite does
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	case nil:
+		if b != nil {
+			return -1
+		}
+	}
+	if b == nil && a != nil {
+		return 1
+	}
+	return 0
+}
+
+func matches(a db.Article, f Filter) bool {
+	v := fieldValue(a, f.Field)
+	if v == nil {
+		return false
+	}
+
+	switch f.Op {
+	case OpEq:
+		return compareValues(v, f.Values[0]) == 0
+	case OpNe:
+		return compareValues(v, f.Values[0]) != 0
+	case OpGt:
+		return compareValues(v, f.Values[0]) > 0
+	case OpGte:
+		return compareValues(v, f.Values[0]) >= 0
+	case OpLt:
+		return compareValues(v, f.Values[0]) < 0
+	case OpLte:
+		return compareValues(v, f.Values[0]) <= 0
+	case OpIn:
+		return slices.ContainsFunc(f.Values, func(value any) bool { return compareValues(v, value) == 0 })
+	case OpContains, OpPrefix:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		s, sub := strings.ToLower(s), strings.ToLower(f.Values[0].(string))
+		if f.Field == "name" {
+			// The SQL stores match name_key, see filterSQL
+			s, sub = a.NameKey, textnorm.NameKey(f.Values[0].(string))
+		}
+		if f.Op == OpPrefix {
+			return strings.HasPrefix(s, sub)
+		}
+		return strings.Contains(s, sub)
+	}
+	return false
+}
+
+// comesAfter tells whether a sorts after the keyset values
+func comesAfter(a db.Article, sort []SortKey, after []any) bool {
+	for i, key := range sort {
+		c := compareValues(fieldValue(a, key.Field), after[i])
+		if key.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c > 0
+		}
+	}
+	return false
+}
+
+func (s *memoryStore) SearchArticles(ctx context.Context, q string, limit, offset int64) ([]SearchResult, error) {
+	phrases := searchPhrases(q)
+	if len(phrases) == 0 {
+		return nil, nil
+	}
+
+	defer s.lock()()
+
+	var results []SearchResult
+	for _, a := range s.sorted(live) {
+		if r, ok := searchArticle(a, phrases); ok {
+			results = append(results, r)
+		}
+	}
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Compare(b.Rank, a.Rank)
+	})
+	return page(results, limit, offset), nil
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+)
+
+// QueryObserver is told how long each query of a store took. The name is
+// the one the query has in db/queries, e.g. GetArticle.
+type QueryObserver func(name string, elapsed time.Duration)
+
+// observedConn times the queries run through it. A query returning rows is
+// timed until the first rows are ready, reading them is not included.
+type observedConn struct {
+	db.DBTX
+	observe QueryObserver
+}
+
+// observe wraps c so that fn sees its queries, a nil fn leaves c as is
+func observe(c db.DBTX, fn QueryObserver) db.DBTX {
+	if fn == nil {
+		return c
+	}
+	return observedConn{DBTX: c, observe: fn}
+}
+
+func (c observedConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer c.done(query, time.Now())
+	return c.DBTX.ExecContext(ctx, query, args...)
+}
+
+func (c observedConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer c.done(query, time.Now())
+	return c.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (c observedConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer c.done(query, time.Now())
+	return c.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func (c observedConn) done(query string, start time.Time) {
+	c.observe(queryName(query), time.Since(start))
+}
+
+// queryName reads the name from the "-- name: GetArticle :one" comment sqlc
+// keeps at the start of every query
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(query), "-- name: ")
+	if !ok {
+		return "unnamed"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
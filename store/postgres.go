@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	pgdb "github.com/hexlet-components/go-gin-example/db/generated/postgres"
+)
+
+// postgresStore runs the PostgreSQL queries. They are written to generate
+// the same structs as the SQLite ones, so the results are converted as is.
+type postgresStore struct {
+	q    *pgdb.Queries
+	conn conn
+	// database is nil within a transaction
+	database *sql.DB
+	observe  QueryObserver
+}
+
+// NewPostgres returns the store of a PostgreSQL database
+func NewPostgres(database *sql.DB) ArticleStore {
+	return newPostgres(database, nil)
+}
+
+func newPostgres(database *sql.DB, fn QueryObserver) *postgresStore {
+	conn := observe(database, fn)
+	return &postgresStore{q: pgdb.New(conn), conn: conn, database: database, observe: fn}
+}
+
+func (s *postgresStore) InTx(ctx context.Context, fn func(ArticleStore) error) error {
+	return inTx(ctx, s.database, func(tx *sql.Tx) error {
+		if tx == nil {
+			return fn(s)
+		}
+		conn := observe(tx, s.observe)
+		return fn(&postgresStore{q: pgdb.New(conn), conn: conn, observe: s.observe})
+	})
+}
+
+// rebind numbers the ? placeholders the way PostgreSQL expects them. LIKE
+// becomes ILIKE to match the case-insensitive LIKE of SQLite.
+func rebind(query string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range strings.ReplaceAll(query, " LIKE ", " ILIKE ") {
+		if r == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func (s *postgresStore) FindArticles(ctx context.Context, q ArticleQuery) ([]db.Article, error) {
+	query, args, err := articleQuerySQL(q)
+	if err != nil {
+		return nil, err
+	}
+	return queryArticles(ctx, s.conn, rebind(query), args...)
+}
+
+// The document is the expression articles_search_idx is built on, so the
+// index is used. ts_rank already grows with relevance.
+const postgresSearchArticles = `-- name: SearchArticles :many
+SELECT a.id, a.name, a.name_key, a.body, a.slug, a.status,
+	a.created_at, a.updated_at, a.published_at, a.version, a.deleted_at,
+	ts_rank(to_tsvector('simple', translate(a.name || ' ' || a.body, 'ёЁ', 'еЕ')), query) AS rank,
+	ts_headline('simple', translate(a.name || ' ' || a.body, 'ёЁ', 'еЕ'), query, $2) AS snippet
+FROM articles a, to_tsquery('simple', $1) AS query
+WHERE to_tsvector('simple', translate(a.name || ' ' || a.body, 'ёЁ', 'еЕ')) @@ query
+	AND a.deleted_at IS NULL
+ORDER BY rank DESC, a.id
+LIMIT $3 OFFSET $4`
+
+// headlineOptions makes ts_headline produce the same snippets as snippet()
+// of FTS5: one fragment of about 16 words
+var headlineOptions = "StartSel=" + HighlightOpen + ", StopSel=" + HighlightClose +
+	", MaxFragments=1, MaxWords=16, MinWords=8, FragmentDelimiter=…"
+
+func (s *postgresStore) SearchArticles(ctx context.Context, q string, limit, offset int64) ([]SearchResult, error) {
+	return querySearchResults(ctx, s.conn, postgresSearchArticles, tsQuery(q), headlineOptions, limit, offset)
+}
+
+// tsQuery is ftsQuery for PostgreSQL: every word is a quoted lexeme, the
+// last one matched as a prefix
+func tsQuery(q string) string {
+	words := strings.Fields(yoReplacer.Replace(q))
+	if len(words) == 0 {
+		return ""
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(w)
+		terms = append(terms, `'`+w+`'`)
+	}
+	terms[len(terms)-1] += ":*"
+
+	return strings.Join(terms, " & ")
+}
+
+func articles(rows []pgdb.Article) []db.Article {
+	result := make([]db.Article, len(rows))
+	for i, row := range rows {
+		result[i] = db.Article(row)
+	}
+	return result
+}
+
+func revisions(rows []pgdb.ArticleRevision) []db.ArticleRevision {
+	result := make([]db.ArticleRevision, len(rows))
+	for i, row := range rows {
+		result[i] = db.ArticleRevision(row)
+	}
+	return result
+}
+
+func (s *postgresStore) AddSlugHistory(ctx context.Context, arg db.AddSlugHistoryParams) error {
+	return s.q.AddSlugHistory(ctx, pgdb.AddSlugHistoryParams(arg))
+}
+
+func (s *postgresStore) CreateArticle(ctx context.Context, arg db.CreateArticleParams) (db.Article, error) {
+	article, err := s.q.CreateArticle(ctx, pgdb.CreateArticleParams(arg))
+	return db.Article(article), err
+}
+
+func (s *postgresStore) CreateRevision(ctx context.Context, arg db.CreateRevisionParams) error {
+	return s.q.CreateRevision(ctx, pgdb.CreateRevisionParams(arg))
+}
+
+func (s *postgresStore) DeleteArticle(ctx context.Context, arg db.DeleteArticleParams) (int64, error) {
+	return s.q.DeleteArticle(ctx, pgdb.DeleteArticleParams(arg))
+}
+
+func (s *postgresStore) DeleteSlugHistory(ctx context.Context, slug string) error {
+	return s.q.DeleteSlugHistory(ctx, slug)
+}
+
+func (s *postgresStore) GetArticle(ctx context.Context, id int64) (db.Article, error) {
+	article, err := s.q.GetArticle(ctx, id)
+	return db.Article(article), err
+}
+
+func (s *postgresStore) GetArticleBySlug(ctx context.Context, slug string) (db.Article, error) {
+	article, err := s.q.GetArticleBySlug(ctx, slug)
+	return db.Article(article), err
+}
+
+func (s *postgresStore) GetArticleWithDeleted(ctx context.Context, id int64) (db.Article, error) {
+	article, err := s.q.GetArticleWithDeleted(ctx, id)
+	return db.Article(article), err
+}
+
+func (s *postgresStore) GetCurrentSlug(ctx context.Context, slug string) (*string, error) {
+	return s.q.GetCurrentSlug(ctx, slug)
+}
+
+func (s *postgresStore) GetRevision(ctx context.Context, arg db.GetRevisionParams) (db.ArticleRevision, error) {
+	revision, err := s.q.GetRevision(ctx, pgdb.GetRevisionParams(arg))
+	return db.ArticleRevision(revision), err
+}
+
+func (s *postgresStore) ListArticles(ctx context.Context) ([]db.Article, error) {
+	rows, err := s.q.ListArticles(ctx)
+	return articles(rows), err
+}
+
+func (s *postgresStore) ListArticlesAfter(ctx context.Context, arg db.ListArticlesAfterParams) ([]db.Article, error) {
+	rows, err := s.q.ListArticlesAfter(ctx, pgdb.ListArticlesAfterParams(arg))
+	return articles(rows), err
+}
+
+func (s *postgresStore) ListArticlesPage(ctx context.Context, arg db.ListArticlesPageParams) ([]db.Article, error) {
+	rows, err := s.q.ListArticlesPage(ctx, pgdb.ListArticlesPageParams(arg))
+	return articles(rows), err
+}
+
+func (s *postgresStore) ListDeletedArticles(ctx context.Context, arg db.ListDeletedArticlesParams) ([]db.Article, error) {
+	rows, err := s.q.ListDeletedArticles(ctx, pgdb.ListDeletedArticlesParams(arg))
+	return articles(rows), err
+}
+
+func (s *postgresStore) ListRevisions(ctx context.Context, arg db.ListRevisionsParams) ([]db.ArticleRevision, error) {
+	rows, err := s.q.ListRevisions(ctx, pgdb.ListRevisionsParams(arg))
+	return revisions(rows), err
+}
+
+func (s *postgresStore) ListTakenSlugs(ctx context.Context, arg db.ListTakenSlugsParams) ([]string, error) {
+	return s.q.ListTakenSlugs(ctx, pgdb.ListTakenSlugsParams(arg))
+}
+
+func (s *postgresStore) PatchArticle(ctx context.Context, arg db.PatchArticleParams) (db.Article, error) {
+	article, err := s.q.PatchArticle(ctx, pgdb.PatchArticleParams(arg))
+	return db.Article(article), err
+}
+
+func (s *postgresStore) PurgeDeletedArticles(ctx context.Context, before *time.Time) (int64, error) {
+	return s.q.PurgeDeletedArticles(ctx, before)
+}
+
+func (s *postgresStore) RestoreArticle(ctx context.Context, id int64) (db.Article, error) {
+	article, err := s.q.RestoreArticle(ctx, id)
+	return db.Article(article), err
+}
+
+func (s *postgresStore) SoftDeleteArticle(ctx context.Context, arg db.SoftDeleteArticleParams) (int64, error) {
+	return s.q.SoftDeleteArticle(ctx, pgdb.SoftDeleteArticleParams(arg))
+}
+
+func (s *postgresStore) UpdateArticle(ctx context.Context, arg db.UpdateArticleParams) (db.Article, error) {
+	article, err := s.q.UpdateArticle(ctx, pgdb.UpdateArticleParams(arg))
+	return db.Article(article), err
+}
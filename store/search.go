@@ -0,0 +1,158 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+)
+
+// snippetTokens is how many words a search snippet holds, as in the FTS5
+// snippet() call
+const snippetTokens = 16
+
+// token is a word of a text: folded for matching, with its byte range in
+// the text
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text into runs of letters and digits, lowercased and with
+// ё folded into е as the search indexes do
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text + " " {
+		wordy := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case wordy && start < 0:
+			start = i
+		case !wordy && start >= 0:
+			word := strings.ToLower(yoReplacer.Replace(text[start:i]))
+			tokens = append(tokens, token{word: word, start: start, end: i})
+			start = -1
+		}
+	}
+	return tokens
+}
+
+// searchPhrases turns free text into the phrases of the in-memory search:
+// every whitespace-separated word is a phrase of its tokens. The last token
+// of the last phrase is matched as a prefix.
+func searchPhrases(q string) [][]string {
+	var phrases [][]string
+	for _, w := range strings.Fields(q) {
+		var phrase []string
+		for _, t := range tokenize(w) {
+			phrase = append(phrase, t.word)
+		}
+		if len(phrase) > 0 {
+			phrases = append(phrases, phrase)
+		}
+	}
+	return phrases
+}
+
+// phraseAt tells whether phrase starts at tokens[i]
+func phraseAt(tokens []token, i int, phrase []string, prefix bool) bool {
+	if i+len(phrase) > len(tokens) {
+		return false
+	}
+	for j, word := range phrase {
+		if prefix && j == len(phrase)-1 {
+			return strings.HasPrefix(tokens[i+j].word, word)
+		}
+		if tokens[i+j].word != word {
+			return false
+		}
+	}
+	return true
+}
+
+// searchArticle matches a against phrases, all of which must occur in its
+// name or body. Rank is the share of matched words in each column, summed.
+func searchArticle(a db.Article, phrases [][]string) (SearchResult, bool) {
+	type column struct {
+		text    string
+		tokens  []token
+		matched []bool
+		count   int
+	}
+
+	columns := make([]*column, 0, 2)
+	for _, text := range []string{a.Name, a.Body} {
+		tokens := tokenize(text)
+		columns = append(columns, &column{text: text, tokens: tokens, matched: make([]bool, len(tokens))})
+	}
+
+	result := SearchResult{Article: a}
+	for p, phrase := range phrases {
+		prefix := p == len(phrases)-1
+		found := false
+		for _, c := range columns {
+			for i := range c.tokens {
+				if !phraseAt(c.tokens, i, phrase, prefix) {
+					continue
+				}
+				found = true
+				for j := range phrase {
+					c.matched[i+j] = true
+				}
+				c.count++
+				result.Rank += 1 / float64(len(c.tokens))
+			}
+		}
+		if !found {
+			return SearchResult{}, false
+		}
+	}
+
+	best := columns[0]
+	for _, c := range columns[1:] {
+		if c.count > best.count {
+			best = c
+		}
+	}
+	result.Snippet = snippet(best.text, best.tokens, best.matched)
+	return result, true
+}
+
+// snippet cuts a window of snippetTokens words from text starting near its
+// first match, with the matched words highlighted
+func snippet(text string, tokens []token, matched []bool) string {
+	first := 0
+	for i, m := range matched {
+		if m {
+			first = i
+			break
+		}
+	}
+	start := max(0, min(first-2, len(tokens)-snippetTokens))
+	end := min(len(tokens), start+snippetTokens)
+	if start >= end {
+		return ""
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := tokens[start].start
+	for i := start; i < end; i++ {
+		t := tokens[i]
+		sb.WriteString(text[pos:t.start])
+		if matched[i] {
+			sb.WriteString(HighlightOpen + text[t.start:t.end] + HighlightClose)
+		} else {
+			sb.WriteString(text[t.start:t.end])
+		}
+		pos = t.end
+	}
+	if end < len(tokens) {
+		sb.WriteString("…")
+	} else {
+		sb.WriteString(text[pos:])
+	}
+	return sb.String()
+}
@@ -0,0 +1,358 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	migrate "github.com/hexlet-components/go-gin-example/db"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+)
+
+// New returns the SQL store for the database pools of dialect, one of the
+// migrate dialects. Empty means SQLite. A non-nil observe is told about every
+// query the store runs.
+func New(dialect string, pools *migrate.Pools, observe QueryObserver) ArticleStore {
+	if dialect == migrate.DialectPostgres {
+		return newPostgres(pools.Writer, observe)
+	}
+	return newSQLite(pools.Writer, pools.Reader, observe)
+}
+
+// conn is what both a database and a transaction can run queries on
+type conn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// inTx runs fn in a transaction of database. A nil database means the
+// caller is in a transaction already, fn then runs as part of it.
+func inTx(ctx context.Context, database *sql.DB, fn func(tx *sql.Tx) error) error {
+	if database == nil {
+		return fn(nil)
+	}
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sqliteStore runs the SQLite queries, search goes through the FTS5 index.
+// Reads that are not part of a transaction go to a separate pool, so they
+// never wait for the writer.
+type sqliteStore struct {
+	*db.Queries
+	reads    *db.Queries
+	readConn conn
+	// database is nil within a transaction
+	database *sql.DB
+	observe  QueryObserver
+}
+
+// NewSQLite returns the store of a SQLite database with a single pool
+func NewSQLite(database *sql.DB) ArticleStore {
+	return NewSQLitePools(database, database)
+}
+
+// NewSQLitePools returns the store of a SQLite database opened as a writer
+// and a reader pool, see migrate.OpenSQLite
+func NewSQLitePools(writer, reader *sql.DB) ArticleStore {
+	return newSQLite(writer, reader, nil)
+}
+
+func newSQLite(writer, reader *sql.DB, fn QueryObserver) *sqliteStore {
+	readConn := observe(reader, fn)
+	return &sqliteStore{
+		Queries:  db.New(observe(writer, fn)),
+		reads:    db.New(readConn),
+		readConn: readConn,
+		database: writer,
+		observe:  fn,
+	}
+}
+
+func (s *sqliteStore) InTx(ctx context.Context, fn func(ArticleStore) error) error {
+	return inTx(ctx, s.database, func(tx *sql.Tx) error {
+		if tx == nil {
+			return fn(s)
+		}
+		conn := observe(tx, s.observe)
+		q := db.New(conn)
+		return fn(&sqliteStore{Queries: q, reads: q, readConn: conn, observe: s.observe})
+	})
+}
+
+func (s *sqliteStore) GetArticle(ctx context.Context, id int64) (db.Article, error) {
+	return s.reads.GetArticle(ctx, id)
+}
+
+func (s *sqliteStore) GetArticleBySlug(ctx context.Context, slug string) (db.Article, error) {
+	return s.reads.GetArticleBySlug(ctx, slug)
+}
+
+func (s *sqliteStore) GetArticleWithDeleted(ctx context.Context, id int64) (db.Article, error) {
+	return s.reads.GetArticleWithDeleted(ctx, id)
+}
+
+func (s *sqliteStore) GetCurrentSlug(ctx context.Context, slug string) (*string, error) {
+	return s.reads.GetCurrentSlug(ctx, slug)
+}
+
+func (s *sqliteStore) GetRevision(ctx context.Context, arg db.GetRevisionParams) (db.ArticleRevision, error) {
+	return s.reads.GetRevision(ctx, arg)
+}
+
+func (s *sqliteStore) ListArticles(ctx context.Context) ([]db.Article, error) {
+	return s.reads.ListArticles(ctx)
+}
+
+func (s *sqliteStore) ListArticlesAfter(ctx context.Context, arg db.ListArticlesAfterParams) ([]db.Article, error) {
+	return s.reads.ListArticlesAfter(ctx, arg)
+}
+
+func (s *sqliteStore) ListArticlesPage(ctx context.Context, arg db.ListArticlesPageParams) ([]db.Article, error) {
+	return s.reads.ListArticlesPage(ctx, arg)
+}
+
+func (s *sqliteStore) ListDeletedArticles(ctx context.Context, arg db.ListDeletedArticlesParams) ([]db.Article, error) {
+	return s.reads.ListDeletedArticles(ctx, arg)
+}
+
+func (s *sqliteStore) ListRevisions(ctx context.Context, arg db.ListRevisionsParams) ([]db.ArticleRevision, error) {
+	return s.reads.ListRevisions(ctx, arg)
+}
+
+func (s *sqliteStore) ListTakenSlugs(ctx context.Context, arg db.ListTakenSlugsParams) ([]string, error) {
+	return s.reads.ListTakenSlugs(ctx, arg)
+}
+
+func (s *sqliteStore) FindArticles(ctx context.Context, q ArticleQuery) ([]db.Article, error) {
+	query, args, err := articleQuerySQL(q)
+	if err != nil {
+		return nil, err
+	}
+	return queryArticles(ctx, s.readConn, query, args...)
+}
+
+// bm25() is negative with the best match first, it is flipped so that
+// clients get a score where bigger means more relevant. The snippet comes from
+// whichever indexed column matched best.
+const sqliteSearchArticles = `-- name: SearchArticles :many
+SELECT a.id, a.name, a.name_key, a.body, a.slug, a.status,
+	a.created_at, a.updated_at, a.published_at, a.version, a.deleted_at,
+	-bm25(articles_fts) AS rank,
+	snippet(articles_fts, -1, ?, ?, '…', 16) AS snippet
+FROM articles_fts
+JOIN articles a ON a.id = articles_fts.rowid
+WHERE articles_fts MATCH ? AND a.deleted_at IS NULL
+ORDER BY bm25(articles_fts), a.id
+LIMIT ? OFFSET ?`
+
+func (s *sqliteStore) SearchArticles(ctx context.Context, q string, limit, offset int64) ([]SearchResult, error) {
+	results, err := querySearchResults(ctx, s.readConn, sqliteSearchArticles,
+		HighlightOpen, HighlightClose, ftsQuery(q), limit, offset)
+	// The index is missing, or left behind by a binary with FTS5 in a
+	// database this one opened
+	if err != nil && (strings.Contains(err.Error(), "no such table: articles_fts") ||
+		strings.Contains(err.Error(), "no such module: fts5")) {
+		return nil, ErrSearchUnavailable
+	}
+	return results, err
+}
+
+// yoReplacer mirrors the normalization the search indexes apply
+var yoReplacer = strings.NewReplacer("ё", "е", "Ё", "Е")
+
+// ftsQuery turns free text into an FTS5 query where every word is a quoted
+// phrase, so user input can never be parsed as FTS5 syntax. The last word is
+// matched as a prefix to make search-as-you-type work.
+func ftsQuery(q string) string {
+	words := strings.Fields(yoReplacer.Replace(q))
+	if len(words) == 0 {
+		return ""
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+strings.ReplaceAll(w, `"`, `""`)+`"`)
+	}
+	terms[len(terms)-1] += "*"
+
+	return strings.Join(terms, " ")
+}
+
+func queryArticles(ctx context.Context, c conn, query string, args ...any) ([]db.Article, error) {
+	rows, err := c.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []db.Article
+	for rows.Next() {
+		var a db.Article
+		err := rows.Scan(
+			&a.ID, &a.Name, &a.NameKey, &a.Body, &a.Slug, &a.Status,
+			&a.CreatedAt, &a.UpdatedAt, &a.PublishedAt, &a.Version, &a.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+func querySearchResults(ctx context.Context, c conn, query string, args ...any) ([]SearchResult, error) {
+	rows, err := c.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		err := rows.Scan(
+			&r.ID, &r.Name, &r.NameKey, &r.Body, &r.Slug, &r.Status,
+			&r.CreatedAt, &r.UpdatedAt, &r.PublishedAt, &r.Version, &r.DeletedAt,
+			&r.Rank, &r.Snippet,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+const articleColumns = "id, name, name_key, body, slug, status, created_at, updated_at, published_at, version, deleted_at"
+
+// articleQuerySQL translates q into a SELECT over articles outside the trash
+// written with ? placeholders. Only ArticleFields ever reach SQL, so column
+// names are never taken from input, and every value is a bound parameter.
+func articleQuerySQL(q ArticleQuery) (string, []any, error) {
+	where := []string{"deleted_at IS NULL"}
+	var args []any
+
+	for _, f := range q.Filters {
+		if !slices.Contains(ArticleFields, f.Field) {
+			return "", nil, fmt.Errorf("unknown field: %q", f.Field)
+		}
+		clause, clauseArgs, err := filterSQL(f)
+		if err != nil {
+			return "", nil, err
+		}
+		where = append(where, clause)
+		args = append(args, clauseArgs...)
+	}
+
+	order := make([]string, 0, len(q.Sort))
+	for _, key := range q.Sort {
+		if !slices.Contains(ArticleFields, key.Field) {
+			return "", nil, fmt.Errorf("unknown field: %q", key.Field)
+		}
+		dir := "ASC"
+		if key.Desc {
+			dir = "DESC"
+		}
+		order = append(order, key.Field+" "+dir)
+	}
+
+	if q.After != nil {
+		if len(q.After) != len(q.Sort) {
+			return "", nil, fmt.Errorf("after has %d values for %d sort keys", len(q.After), len(q.Sort))
+		}
+		clause, clauseArgs := keysetSQL(q.Sort, q.After)
+		where = append(where, clause)
+		args = append(args, clauseArgs...)
+	}
+
+	var sb strings.Builder
+	// Named like the sqlc queries, see QueryObserver
+	sb.WriteString("-- name: FindArticles :many\n")
+	sb.WriteString("SELECT " + articleColumns + " FROM articles")
+	sb.WriteString(" WHERE " + strings.Join(where, " AND "))
+	if len(order) > 0 {
+		sb.WriteString(" ORDER BY " + strings.Join(order, ", "))
+	}
+
+	sb.WriteString(" LIMIT ?")
+	args = append(args, q.Limit)
+	if q.After == nil {
+		sb.WriteString(" OFFSET ?")
+		args = append(args, q.Offset)
+	}
+
+	return sb.String(), args, nil
+}
+
+var comparisons = map[string]string{
+	OpEq: "=", OpNe: "<>", OpGt: ">", OpGte: ">=", OpLt: "<", OpLte: "<=",
+}
+
+func filterSQL(f Filter) (string, []any, error) {
+	if op, ok := comparisons[f.Op]; ok && len(f.Values) == 1 {
+		return fmt.Sprintf("%s %s ?", f.Field, op), f.Values, nil
+	}
+
+	switch {
+	case f.Op == OpIn && len(f.Values) > 0:
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(f.Values)), ", ")
+		return fmt.Sprintf("%s IN (%s)", f.Field, placeholders), f.Values, nil
+	case (f.Op == OpContains || f.Op == OpPrefix) && len(f.Values) == 1:
+		s, ok := f.Values[0].(string)
+		if !ok {
+			break
+		}
+		field := f.Field
+		if field == "name" {
+			// LIKE only folds ASCII, name_key is the name folded for any
+			// script, so the value is folded the same way
+			field, s = "name_key", textnorm.NameKey(s)
+		}
+		pattern := escapeLike(s) + "%"
+		if f.Op == OpContains {
+			pattern = "%" + pattern
+		}
+		return fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, field), []any{pattern}, nil
+	}
+	return "", nil, fmt.Errorf("invalid filter: %s[%s] with %d value(s)", f.Field, f.Op, len(f.Values))
+}
+
+// keysetSQL builds the "comes after the cursor" predicate for the sort keys:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetSQL(sort []SortKey, values []any) (string, []any) {
+	var terms []string
+	var args []any
+	for i, key := range sort {
+		var parts []string
+		for j := range i {
+			parts = append(parts, sort[j].Field+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if key.Desc {
+			op = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s ?", key.Field, op))
+		args = append(args, values[i])
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
@@ -0,0 +1,89 @@
+// Package store is where the handlers keep articles. ArticleStore has a SQL
+// implementation for every supported database and an in-memory one for
+// tests and demos.
+package store
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+)
+
+// ErrSearchUnavailable is returned by SearchArticles when the store has no
+// full-text index, e.g. SQLite built without FTS5
+var ErrSearchUnavailable = errors.New("full-text search is not available in this build")
+
+// Search snippets wrap the matched terms in these
+const (
+	HighlightOpen  = "<mark>"
+	HighlightClose = "</mark>"
+)
+
+// ArticleStore is the storage of articles with their slug history and
+// revisions. The methods of db.Querier behave as the generated queries do:
+// a missing row is sql.ErrNoRows and a duplicate is an error that
+// IsUniqueViolation recognizes.
+type ArticleStore interface {
+	db.Querier
+	// InTx runs fn with a store bound to a transaction, committing when fn
+	// succeeds and rolling back otherwise
+	InTx(ctx context.Context, fn func(ArticleStore) error) error
+	// FindArticles lists the articles outside the trash that match q
+	FindArticles(ctx context.Context, q ArticleQuery) ([]db.Article, error)
+	// SearchArticles runs a full-text search for the words of q, the last
+	// one matched as a prefix, best matches first
+	SearchArticles(ctx context.Context, q string, limit, offset int64) ([]SearchResult, error)
+}
+
+// SearchResult is an article matched by full-text search. Rank grows with
+// relevance, Snippet is the matching fragment with terms wrapped in <mark>.
+type SearchResult struct {
+	db.Article
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// The filter operators of ArticleQuery. Contains and Prefix ignore case, on
+// name in any script as it is matched by its name key, on other fields only
+// ASCII case is certain. In takes any number of values, the rest exactly one.
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpContains = "contains"
+	OpPrefix   = "prefix"
+	OpIn       = "in"
+)
+
+// Filter compares an article field with values of its Go type: int64 for
+// id, time.Time for the timestamps and string for the rest. NULL fields
+// never match.
+type Filter struct {
+	Field  string
+	Op     string
+	Values []any
+}
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ArticleQuery selects a page of articles. Sort must end with a unique
+// field for After to work: it holds the Sort values of the last article of
+// the previous page, Offset is ignored then.
+type ArticleQuery struct {
+	Filters []Filter
+	Sort    []SortKey
+	After   []any
+	Limit   int64
+	Offset  int64
+}
+
+// ArticleFields are the fields ArticleQuery can filter and sort on, they
+// share the names of their columns
+var ArticleFields = []string{"id", "name", "body", "slug", "status", "created_at", "updated_at", "published_at"}
@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sendArticle(t *testing.T, router http.Handler, method, url, body string) (*httptest.ResponseRecorder, db.Article) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var article db.Article
+	if w.Code < http.StatusBadRequest {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &article))
+	}
+	return w, article
+}
+
+func TestArticleStatusLifecycle(t *testing.T) {
+	router := setupTestRouter(t)
+
+	w, article := sendArticle(t, router, "POST", "/articles",
+		`{"name":"Черновик","body":"Текст","slug":"chernovik"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "Текст", article.Body)
+	assert.Equal(t, "chernovik", *article.Slug)
+	assert.Equal(t, handlers.StatusDraft, article.Status)
+	assert.Equal(t, article.CreatedAt, article.UpdatedAt)
+	assert.Nil(t, article.PublishedAt)
+
+	w, published := sendArticle(t, router, "PUT", "/articles/1",
+		`{"name":"Черновик","body":"Текст","slug":"chernovik","status":"published"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, article.CreatedAt, published.CreatedAt)
+	assert.True(t, published.UpdatedAt.After(article.UpdatedAt))
+	require.NotNil(t, published.PublishedAt)
+	assert.Equal(t, published.UpdatedAt, *published.PublishedAt)
+
+	w, archived := sendArticle(t, router, "PUT", "/articles/1",
+		`{"name":"Черновик","status":"archived"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, archived.PublishedAt)
+	assert.Equal(t, *published.PublishedAt, *archived.PublishedAt)
+	assert.Equal(t, "", archived.Body)
+	assert.Equal(t, "chernovik", *archived.Slug)
+
+	w, draft := sendArticle(t, router, "PUT", "/articles/1", `{"name":"Черновик"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handlers.StatusDraft, draft.Status)
+	assert.Nil(t, draft.PublishedAt)
+}
+
+func TestArticleFieldValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedField  string
+	}{
+		{name: "unknown status", body: `{"name":"A","status":"deleted"}`, expectedStatus: http.StatusBadRequest, expectedField: "status"},
+		{name: "slug with uppercase", body: `{"name":"A","slug":"Hello"}`, expectedStatus: http.StatusBadRequest, expectedField: "slug"},
+		{name: "slug with cyrillic", body: `{"name":"A","slug":"привет"}`, expectedStatus: http.StatusBadRequest, expectedField: "slug"},
+		{name: "slug with double hyphen", body: `{"name":"A","slug":"a--b"}`, expectedStatus: http.StatusBadRequest, expectedField: "slug"},
+		{name: "duplicate slug", body: `{"name":"B","slug":"taken"}`, expectedStatus: http.StatusConflict, expectedField: "slug"},
+		{name: "valid slug", body: `{"name":"B","slug":"go-1-26"}`, expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+			w, _ := sendArticle(t, router, "POST", "/articles", `{"name":"Taken","slug":"taken"}`)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			w, _ = sendArticle(t, router, "POST", "/articles", tt.body)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedField != "" {
+				var problem handlers.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				if problem.Field != "" {
+					assert.Equal(t, tt.expectedField, problem.Field)
+				} else {
+					require.Len(t, problem.Errors, 1)
+					assert.Equal(t, tt.expectedField, problem.Errors[0].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestListArticlesByStatusAndTime(t *testing.T) {
+	router := setupTestRouter(t)
+	for _, body := range []string{
+		`{"name":"First","status":"published"}`,
+		`{"name":"Second"}`,
+		`{"name":"Third","status":"published"}`,
+	} {
+		w, _ := sendArticle(t, router, "POST", "/articles", body)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	w, page := getPage(t, router, "/articles?status=published&sort=-created_at")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{3, 1}, ids(page.Items))
+
+	since := page.Items[1].CreatedAt.Format(time.RFC3339Nano)
+	w, page = getPage(t, router, "/articles?created_at[gt]="+since)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{2, 3}, ids(page.Items))
+
+	var seen []int64
+	url := "/articles?sort=created_at&limit=1"
+	for range 4 {
+		w, page := getPage(t, router, url)
+		require.Equal(t, http.StatusOK, w.Code)
+		seen = append(seen, ids(page.Items)...)
+		if page.NextCursor == nil {
+			break
+		}
+		url = "/articles?sort=created_at&limit=1&after=" + *page.NextCursor
+	}
+	assert.Equal(t, []int64{1, 2, 3}, seen)
+
+	w, _ = getPage(t, router, "/articles?sort=published_at")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = getPage(t, router, "/articles?created_at[gt]=yesterday")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
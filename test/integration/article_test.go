@@ -3,12 +3,15 @@ package integration
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/handlers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateArticle(t *testing.T) {
@@ -22,7 +25,7 @@ func TestCreateArticle(t *testing.T) {
 			name:           "create article success",
 			body:           `{"name":"Test Article"}`,
 			expectedStatus: http.StatusCreated,
-			expectedBody:   `{"id":1,"name":"Test Article"}`,
+			expectedBody:   `{"id":1,"name":"Test Article","body":"","slug":"test-article","status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":1,"deleted_at":null}`,
 		},
 		{
 			name:           "create article empty body",
@@ -63,7 +66,7 @@ func TestCreateArticle(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
 			if tt.expectedBody != "" {
-				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+				assertArticleJSON(t, tt.expectedBody, w.Body.String())
 			}
 		})
 	}
@@ -81,9 +84,9 @@ func TestGetArticle(t *testing.T) {
 			name:           "get article success",
 			url:            "/articles/1",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"id":1,"name":"Test Article"}`,
+			expectedBody:   `{"id":1,"name":"Test Article","body":"","slug":null,"status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":1,"deleted_at":null}`,
 			setup: func(queries *db.Queries) int64 {
-				article, err := queries.CreateArticle(context.Background(), "Test Article")
+				article, err := queries.CreateArticle(context.Background(), articleParams("Test Article"))
 				if err != nil {
 					t.Fatalf("failed to create test article: %v", err)
 				}
@@ -122,7 +125,7 @@ func TestGetArticle(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
 			if tt.expectedBody != "" {
-				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+				assertArticleJSON(t, tt.expectedBody, w.Body.String())
 			}
 		})
 	}
@@ -138,14 +141,14 @@ func TestListArticles(t *testing.T) {
 		{
 			name:           "list articles empty",
 			expectedStatus: http.StatusOK,
-			expectedBody:   "null",
+			expectedBody:   `{"items":[],"next_cursor":null}`,
 		},
 		{
 			name:           "list articles with single article",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `[{"id":1,"name":"Single Article"}]`,
+			expectedBody:   `{"items":[{"id":1,"name":"Single Article","body":"","slug":null,"status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":1,"deleted_at":null}],"next_cursor":null}`,
 			setup: func(queries *db.Queries) {
-				_, err := queries.CreateArticle(context.Background(), "Single Article")
+				_, err := queries.CreateArticle(context.Background(), articleParams("Single Article"))
 				if err != nil {
 					t.Fatalf("failed to create test article: %v", err)
 				}
@@ -154,10 +157,10 @@ func TestListArticles(t *testing.T) {
 		{
 			name:           "list articles with multiple articles",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `[{"id":1,"name":"Article 1"},{"id":2,"name":"Article 2"}]`,
+			expectedBody:   `{"items":[{"id":1,"name":"Article 1","body":"","slug":null,"status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":1,"deleted_at":null},{"id":2,"name":"Article 2","body":"","slug":null,"status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":1,"deleted_at":null}],"next_cursor":null}`,
 			setup: func(queries *db.Queries) {
 				for _, name := range []string{"Article 1", "Article 2"} {
-					_, err := queries.CreateArticle(context.Background(), name)
+					_, err := queries.CreateArticle(context.Background(), articleParams(name))
 					if err != nil {
 						t.Fatalf("failed to create test article: %v", err)
 					}
@@ -179,7 +182,7 @@ func TestListArticles(t *testing.T) {
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
-			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assertArticleJSON(t, tt.expectedBody, w.Body.String())
 		})
 	}
 }
@@ -198,9 +201,9 @@ func TestUpdateArticle(t *testing.T) {
 			url:            "/articles/1",
 			body:           `{"name":"Updated Article"}`,
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"id":1,"name":"Updated Article"}`,
+			expectedBody:   `{"id":1,"name":"Updated Article","body":"","slug":"updated-article","status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":2,"deleted_at":null}`,
 			setup: func(queries *db.Queries) int64 {
-				article, err := queries.CreateArticle(context.Background(), "Original Article")
+				article, err := queries.CreateArticle(context.Background(), articleParams("Original Article"))
 				if err != nil {
 					t.Fatalf("failed to create test article: %v", err)
 				}
@@ -225,7 +228,7 @@ func TestUpdateArticle(t *testing.T) {
 			body:           "",
 			expectedStatus: http.StatusBadRequest,
 			setup: func(queries *db.Queries) int64 {
-				article, err := queries.CreateArticle(context.Background(), "Original Article")
+				article, err := queries.CreateArticle(context.Background(), articleParams("Original Article"))
 				if err != nil {
 					t.Fatalf("failed to create test article: %v", err)
 				}
@@ -238,7 +241,7 @@ func TestUpdateArticle(t *testing.T) {
 			body:           `{"name":}`,
 			expectedStatus: http.StatusBadRequest,
 			setup: func(queries *db.Queries) int64 {
-				article, err := queries.CreateArticle(context.Background(), "Original Article")
+				article, err := queries.CreateArticle(context.Background(), articleParams("Original Article"))
 				if err != nil {
 					t.Fatalf("failed to create test article: %v", err)
 				}
@@ -273,7 +276,7 @@ func TestUpdateArticle(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
 			if tt.expectedBody != "" {
-				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+				assertArticleJSON(t, tt.expectedBody, w.Body.String())
 			}
 		})
 	}
@@ -292,7 +295,7 @@ func TestDeleteArticle(t *testing.T) {
 			url:            "/articles/1",
 			expectedStatus: http.StatusNoContent,
 			setup: func(queries *db.Queries) int64 {
-				article, err := queries.CreateArticle(context.Background(), "To Delete")
+				article, err := queries.CreateArticle(context.Background(), articleParams("To Delete"))
 				if err != nil {
 					t.Fatalf("failed to create test article: %v", err)
 				}
@@ -332,3 +335,46 @@ func TestDeleteArticle(t *testing.T) {
 		})
 	}
 }
+
+func TestArticleNameConflict(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{name: "create exact duplicate", method: "POST", url: "/articles", body: `{"name":"Введение в Go"}`, expectedStatus: http.StatusConflict},
+		{name: "create duplicate in other case", method: "POST", url: "/articles", body: `{"name":"ВВЕДЕНИЕ В GO"}`, expectedStatus: http.StatusConflict},
+		{name: "create duplicate with fullwidth letters", method: "POST", url: "/articles", body: `{"name":"Введение в Ｇｏ"}`, expectedStatus: http.StatusConflict},
+		{name: "create duplicate with extra spaces", method: "POST", url: "/articles", body: `{"name":"Введение  в Go"}`, expectedStatus: http.StatusConflict},
+		{name: "create different name", method: "POST", url: "/articles", body: `{"name":"Введение в Rust"}`, expectedStatus: http.StatusCreated},
+		{name: "rename onto existing name", method: "PUT", url: "/articles/2", body: `{"name":"введение в go"}`, expectedStatus: http.StatusConflict},
+		{name: "rename to own name in other case", method: "PUT", url: "/articles/1", body: `{"name":"ВВЕДЕНИЕ В GO"}`, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, queries := setupTestRouterWithQueries(t)
+			for _, name := range []string{"Введение в Go", "Горутины"} {
+				_, err := queries.CreateArticle(context.Background(), articleParams(name))
+				if err != nil {
+					t.Fatalf("failed to create test article: %v", err)
+				}
+			}
+
+			req, _ := http.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusConflict {
+				var problem handlers.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, handlers.ProblemTypeBase+"article-exists", problem.Type)
+				assert.Equal(t, "name", problem.Field)
+			}
+		})
+	}
+}
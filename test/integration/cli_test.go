@@ -0,0 +1,228 @@
+package integration
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hexlet-components/go-gin-example/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLIExitCodes(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "app.db")
+	database, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	require.NoError(t, applyMigrations(database))
+	require.NoError(t, database.Close())
+
+	behindPath := filepath.Join(t.TempDir(), "behind.db")
+	require.Equal(t, cmd.ExitOK, cmd.Run([]string{"migrate", "up-to", "5", "--db", behindPath}, &bytes.Buffer{}, &bytes.Buffer{}))
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedOutput string
+		expectedError  string
+	}{
+		{name: "version", args: []string{"version"}, expectedCode: cmd.ExitOK, expectedOutput: "go-gin-example dev"},
+		{name: "help", args: []string{"--help"}, expectedCode: cmd.ExitOK, expectedOutput: "migrate"},
+		{name: "seed", args: []string{"seed", "--db", dbPath}, expectedCode: cmd.ExitOK, expectedOutput: "Seeded 5 article(s), 0 already existed"},
+		{name: "seed again", args: []string{"--db", dbPath, "seed"}, expectedCode: cmd.ExitOK, expectedOutput: "Seeded 0 article(s), 5 already existed"},
+		{name: "purge", args: []string{"purge", "--db", dbPath, "--retention", "7d"}, expectedCode: cmd.ExitOK, expectedOutput: "Purged 0 article(s)"},
+		{name: "missing database", args: []string{"seed", "--db", filepath.Join(t.TempDir(), "none.db")}, expectedCode: cmd.ExitError, expectedError: "database file does not exist"},
+		{name: "no subcommand", args: []string{}, expectedCode: cmd.ExitUsage, expectedError: "requires a subcommand"},
+		{name: "unknown command", args: []string{"deploy"}, expectedCode: cmd.ExitUsage, expectedError: `unknown command "deploy"`},
+		{name: "unknown migrate command", args: []string{"migrate", "sideways"}, expectedCode: cmd.ExitUsage, expectedError: `unknown command "sideways"`},
+		{name: "unknown flag", args: []string{"serve", "--verbose"}, expectedCode: cmd.ExitUsage, expectedError: "unknown flag: --verbose"},
+		{name: "invalid retention", args: []string{"purge", "--retention", "soon"}, expectedCode: cmd.ExitUsage, expectedError: "invalid duration"},
+		{name: "invalid version", args: []string{"migrate", "to", "latest"}, expectedCode: cmd.ExitUsage, expectedError: "invalid version"},
+		{name: "missing argument", args: []string{"migrate", "to"}, expectedCode: cmd.ExitUsage, expectedError: "accepts 1 arg(s)"},
+		{name: "pending migrations", args: []string{"serve", "--db", behindPath}, expectedCode: cmd.ExitError, expectedError: "migration(s) pending"},
+		{name: "negative timeout", args: []string{"serve", "--db", dbPath, "--shutdown-timeout", "-1s"}, expectedCode: cmd.ExitUsage, expectedError: "shutdown timeout cannot be negative"},
+		{name: "zero shutdown timeout", args: []string{"serve", "--db", dbPath, "--shutdown-timeout", "0"}, expectedCode: cmd.ExitUsage, expectedError: "shutdown timeout must be positive"},
+		{name: "invalid migration type", args: []string{"migrate", "create", "x", "yaml"}, expectedCode: cmd.ExitUsage, expectedError: "must be sql or go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cmd.Run(tt.args, &stdout, &stderr)
+
+			assert.Equal(t, tt.expectedCode, code, stderr.String())
+			assert.Contains(t, stdout.String(), tt.expectedOutput)
+			assert.Contains(t, stderr.String(), tt.expectedError)
+			if tt.expectedCode == cmd.ExitUsage {
+				assert.Contains(t, stderr.String(), "Usage:")
+			}
+		})
+	}
+}
+
+func TestCLIMigrateEmbedded(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "embedded migrations", args: []string{"migrate", "up"}, expectedCode: cmd.ExitOK},
+		{name: "directory on disk", args: []string{"migrate", "up", "--migrations-dir", "../../db/migrations"}, expectedCode: cmd.ExitOK},
+		{name: "missing directory", args: []string{"migrate", "up", "--migrations-dir", "no-such-dir"}, expectedCode: cmd.ExitError, expectedError: "no-such-dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "app.db")
+			var stdout, stderr bytes.Buffer
+			code := cmd.Run(append(tt.args, "--db", dbPath), &stdout, &stderr)
+
+			assert.Equal(t, tt.expectedCode, code, stderr.String())
+			assert.Contains(t, stderr.String(), tt.expectedError)
+			if tt.expectedCode != cmd.ExitOK {
+				return
+			}
+
+			database, err := sql.Open("sqlite3", dbPath)
+			require.NoError(t, err)
+			defer database.Close()
+			var revisions int
+			require.NoError(t, database.QueryRow(`SELECT count(*) FROM article_revisions`).Scan(&revisions))
+		})
+	}
+}
+
+func TestCLIMigrateCommands(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "app.db")
+	migrationsDir := t.TempDir()
+
+	// The steps share the database and run in order
+	steps := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedOutput string
+		expectedError  string
+	}{
+		{name: "dry run on a missing database", args: []string{"migrate", "up", "--dry-run"}, expectedOutput: "-- Up 001_create_articles.sql\nCREATE TABLE"},
+		{name: "dry run creates nothing", args: []string{"migrate", "version"}, expectedOutput: "Current version: 0"},
+		{name: "up to", args: []string{"migrate", "up-to", "3"}, expectedOutput: "Migration command completed: up-to 3"},
+		{name: "version", args: []string{"migrate", "version"}, expectedOutput: "Current version: 3"},
+		{name: "dry run down", args: []string{"migrate", "down-to", "1", "--dry-run"}, expectedOutput: "-- Down 003_add_articles_name_key.sql\nALTER TABLE articles DROP COLUMN name_key;\n\n-- Down 002_create_articles_search.go\n-- Go migration"},
+		{name: "dry run reset keeps the database", args: []string{"migrate", "reset", "--dry-run"}, expectedOutput: "-- Up 001_create_articles.sql"},
+		{name: "down to", args: []string{"migrate", "down-to", "1"}, expectedOutput: "Migration command completed: down-to 1"},
+		{name: "version after down to", args: []string{"migrate", "version"}, expectedOutput: "Current version: 1"},
+		{name: "redo", args: []string{"migrate", "redo"}, expectedOutput: "Migration command completed: redo"},
+		{name: "dry run redo", args: []string{"migrate", "redo", "--dry-run"}, expectedOutput: "-- Down 001_create_articles.sql\nDROP TABLE IF EXISTS articles;\n\n-- Up 001_create_articles.sql"},
+		{name: "nothing to run", args: []string{"migrate", "up-to", "1", "--dry-run"}, expectedOutput: "-- no migrations to run, current version: 1"},
+		{name: "create in a directory", args: []string{"migrate", "create", "Add tags", "--dir", migrationsDir}, expectedOutput: filepath.Join(migrationsDir, "001_add_tags.sql")},
+		{name: "create numbers sequentially", args: []string{"migrate", "create", "backfill-tags", "go", "--dir", migrationsDir}, expectedOutput: filepath.Join(migrationsDir, "002_backfill_tags.go")},
+		{name: "invalid target", args: []string{"migrate", "down-to", "latest"}, expectedCode: cmd.ExitUsage, expectedError: "invalid version"},
+	}
+
+	for _, step := range steps {
+		var stdout, stderr bytes.Buffer
+		code := cmd.Run(append(step.args, "--db", dbPath), &stdout, &stderr)
+
+		assert.Equal(t, step.expectedCode, code, step.name+": "+stderr.String())
+		assert.Contains(t, stdout.String(), step.expectedOutput, step.name)
+		assert.Contains(t, stderr.String(), step.expectedError, step.name)
+	}
+}
+
+func TestCLIMigrateBackups(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "app.db")
+	backupsDir := filepath.Join(dir, "backups")
+
+	run := func(args ...string) string {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		code := cmd.Run(append(args, "--db", dbPath, "--keep-backups", "2"), &stdout, &stderr)
+		require.Equal(t, cmd.ExitOK, code, stderr.String())
+		return stdout.String()
+	}
+	backups := func() []string {
+		t.Helper()
+		paths, err := filepath.Glob(filepath.Join(backupsDir, "app-*.db"))
+		require.NoError(t, err)
+		return paths
+	}
+	countArticles := func() int {
+		t.Helper()
+		database, err := sql.Open("sqlite3", dbPath)
+		require.NoError(t, err)
+		defer database.Close()
+		var count int
+		require.NoError(t, database.QueryRow(`SELECT count(*) FROM articles`).Scan(&count))
+		return count
+	}
+
+	// Nothing to back up before the database exists
+	assert.NotContains(t, run("migrate", "reset"), "Backup saved to")
+	assert.Empty(t, backups())
+
+	require.Equal(t, cmd.ExitOK, cmd.Run([]string{"seed", "--db", dbPath}, &bytes.Buffer{}, &bytes.Buffer{}))
+	assert.Contains(t, run("migrate", "down"), "Backup saved to "+backupsDir)
+	require.Len(t, backups(), 1)
+	seeded := backups()[0]
+
+	assert.NotContains(t, run("migrate", "redo", "--dry-run"), "Backup saved to")
+	assert.NotContains(t, run("migrate", "redo", "--no-backup"), "Backup saved to")
+	require.Len(t, backups(), 1)
+
+	run("migrate", "reset")
+	assert.Equal(t, 0, countArticles())
+
+	run("migrate", "restore", filepath.Base(seeded))
+	assert.Equal(t, 5, countArticles())
+	assert.Len(t, backups(), 3, "the restored backup is not pruned")
+
+	assert.Contains(t, run("migrate", "backup"), "Backup saved to "+backupsDir)
+	assert.Len(t, backups(), 2, "older backups are pruned")
+	assert.NotContains(t, backups(), seeded)
+
+	var stderr bytes.Buffer
+	code := cmd.Run([]string{"migrate", "restore", "missing.db", "--db", dbPath}, &bytes.Buffer{}, &stderr)
+	assert.Equal(t, cmd.ExitError, code)
+	assert.Contains(t, stderr.String(), "backup does not exist")
+}
+
+// TestCLIMigrateBackupsSharedDir checks that retention only counts the
+// backups of its own database when names share a prefix
+func TestCLIMigrateBackupsSharedDir(t *testing.T) {
+	dir := t.TempDir()
+	backupsDir := filepath.Join(dir, "backups")
+
+	backup := func(name string) string {
+		t.Helper()
+		dbPath := filepath.Join(dir, name)
+		require.Equal(t, cmd.ExitOK, cmd.Run([]string{"migrate", "up", "--db", dbPath}, io.Discard, io.Discard))
+		var stdout, stderr bytes.Buffer
+		code := cmd.Run([]string{"migrate", "backup", "--db", dbPath, "--keep-backups", "2"}, &stdout, &stderr)
+		require.Equal(t, cmd.ExitOK, code, stderr.String())
+		path, ok := strings.CutPrefix(strings.TrimSpace(stdout.String()), "Backup saved to ")
+		require.True(t, ok, stdout.String())
+		return path
+	}
+	backups := func(pattern string) int {
+		t.Helper()
+		paths, err := filepath.Glob(filepath.Join(backupsDir, pattern))
+		require.NoError(t, err)
+		return len(paths)
+	}
+
+	for range 3 {
+		backup("app-old.db")
+	}
+	latest := backup("app.db")
+
+	assert.FileExists(t, latest, "the backup just made is kept")
+	assert.Equal(t, 2, backups("app-old-*.db"), "the other database keeps its backups")
+	assert.Equal(t, 1, backups("app-2*.db"))
+}
@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	migrate "github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics(t *testing.T) {
+	versions, err := migrate.MigrationVersions(nil)
+	require.NoError(t, err)
+	latest := versions[len(versions)-1]
+
+	tests := []struct {
+		name            string
+		migrations      []int64
+		expectedPending []int64
+		expectedLatest  int64
+	}{
+		{name: "up to date", migrations: versions, expectedPending: []int64{}, expectedLatest: latest},
+		{name: "database behind the binary", migrations: append(versions[:len(versions):len(versions)], latest+1), expectedPending: []int64{latest + 1}, expectedLatest: latest + 1},
+		{name: "database ahead of the binary", migrations: versions[:1], expectedPending: []int64{}, expectedLatest: versions[0]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRouter(setupTestDB(t), handlers.RouterConfig{Migrations: tt.migrations})
+
+			w := conditionalRequest(router, "GET", "/diagnostics", "", "", "", "")
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var diagnostics handlers.Diagnostics
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diagnostics))
+			assert.Equal(t, latest, diagnostics.Schema.Version)
+			assert.Equal(t, tt.expectedLatest, diagnostics.Schema.Latest)
+			assert.Equal(t, tt.expectedPending, diagnostics.Schema.Pending)
+			assert.Equal(t, "dev", diagnostics.Build.Version)
+		})
+	}
+}
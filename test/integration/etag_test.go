@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conditionalRequest(router http.Handler, method, url, header, value, contentType, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestArticleETag(t *testing.T) {
+	router := setupTestRouter(t)
+
+	w, _ := sendArticle(t, router, "POST", "/articles", `{"name":"Test Article"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = conditionalRequest(router, "GET", "/articles/1", "", "", "", "")
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = conditionalRequest(router, "GET", "/articles/by-slug/test-article", "", "", "", "")
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w, _ = sendArticle(t, router, "PUT", "/articles/1", `{"name":"Test Article","body":"Text"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = conditionalRequest(router, "PATCH", "/articles/1", "", "", handlers.MergePatchContentType, `{"body":"More"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestArticleIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{name: "current etag", ifNoneMatch: `"1"`, expectedStatus: http.StatusNotModified},
+		{name: "weak current etag", ifNoneMatch: `W/"1"`, expectedStatus: http.StatusNotModified},
+		{name: "list with current etag", ifNoneMatch: `"7", "1"`, expectedStatus: http.StatusNotModified},
+		{name: "any", ifNoneMatch: `*`, expectedStatus: http.StatusNotModified},
+		{name: "stale etag", ifNoneMatch: `"0"`, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+			w, _ := sendArticle(t, router, "POST", "/articles", `{"name":"Test Article"}`)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			w = conditionalRequest(router, "GET", "/articles/1", "If-None-Match", tt.ifNoneMatch, "", "")
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestArticleIfMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		contentType    string
+		body           string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{name: "put with current etag", method: "PUT", url: "/articles/1", contentType: "application/json", body: `{"name":"Updated"}`, ifMatch: `"1"`, expectedStatus: http.StatusOK},
+		{name: "put with any", method: "PUT", url: "/articles/1", contentType: "application/json", body: `{"name":"Updated"}`, ifMatch: `*`, expectedStatus: http.StatusOK},
+		{name: "put with stale etag", method: "PUT", url: "/articles/1", contentType: "application/json", body: `{"name":"Updated"}`, ifMatch: `"0"`, expectedStatus: http.StatusPreconditionFailed},
+		{name: "put with weak etag", method: "PUT", url: "/articles/1", contentType: "application/json", body: `{"name":"Updated"}`, ifMatch: `W/"1"`, expectedStatus: http.StatusPreconditionFailed},
+		{name: "put to missing article", method: "PUT", url: "/articles/42", contentType: "application/json", body: `{"name":"Updated"}`, ifMatch: `"1"`, expectedStatus: http.StatusNotFound},
+		{name: "patch with current etag", method: "PATCH", url: "/articles/1", contentType: handlers.MergePatchContentType, body: `{"name":"Updated"}`, ifMatch: `"1"`, expectedStatus: http.StatusOK},
+		{name: "patch with stale etag", method: "PATCH", url: "/articles/1", contentType: handlers.MergePatchContentType, body: `{"name":"Updated"}`, ifMatch: `"2", "3"`, expectedStatus: http.StatusPreconditionFailed},
+		{name: "delete with current etag", method: "DELETE", url: "/articles/1", ifMatch: `"1"`, expectedStatus: http.StatusNoContent},
+		{name: "delete with stale etag", method: "DELETE", url: "/articles/1", ifMatch: `"0"`, expectedStatus: http.StatusPreconditionFailed},
+		{name: "delete missing article", method: "DELETE", url: "/articles/42", ifMatch: `*`, expectedStatus: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, queries := setupTestRouterWithQueries(t)
+			_, err := queries.CreateArticle(t.Context(), articleParams("Test Article"))
+			require.NoError(t, err)
+
+			w := conditionalRequest(router, tt.method, tt.url, "If-Match", tt.ifMatch, tt.contentType, tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusPreconditionFailed {
+				assert.Contains(t, w.Body.String(), handlers.ProblemTypeBase+"precondition-failed")
+
+				article, err := queries.GetArticle(t.Context(), 1)
+				require.NoError(t, err)
+				assert.Equal(t, "Test Article", article.Name)
+				assert.Equal(t, int64(1), article.Version)
+			}
+		})
+	}
+}
+
+func TestUpdateArticleStaleVersion(t *testing.T) {
+	queries, _ := setupTestQueries(t)
+
+	article, err := queries.CreateArticle(t.Context(), articleParams("Test Article"))
+	require.NoError(t, err)
+
+	params := db.UpdateArticleParams{
+		ID:      article.ID,
+		Name:    "First",
+		NameKey: "first",
+		Status:  handlers.StatusDraft,
+		Now:     article.CreatedAt,
+		Version: article.Version,
+	}
+	updated, err := queries.UpdateArticle(t.Context(), params)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	// A second writer that read the same version loses
+	params.Name, params.NameKey = "Second", "second"
+	_, err = queries.UpdateArticle(t.Context(), params)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
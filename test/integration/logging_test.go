@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/hexlet-components/go-gin-example/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logRecords decodes the JSON lines of a log
+func logRecords(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for line := range strings.Lines(logs.String()) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRequestLogger(t *testing.T) {
+	var logs bytes.Buffer
+	cfg := routerConfig
+	cfg.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+	r := newRouter(setupTestDB(t), cfg)
+	r.GET("/echo", func(c *gin.Context) {
+		handlers.Logger(c).Info("handled")
+		c.String(http.StatusOK, "echo")
+	})
+
+	tests := []struct {
+		name              string
+		method            string
+		path              string
+		requestID         string
+		expectedRoute     string
+		expectedStatus    int
+		expectedLevel     string
+		expectedRecords   int
+		expectedRequestID string
+	}{
+		{name: "route template", method: "GET", path: "/articles/999", requestID: "req-1", expectedRoute: "/articles/:id", expectedStatus: http.StatusNotFound, expectedLevel: "INFO", expectedRecords: 1, expectedRequestID: "req-1"},
+		{name: "list", method: "GET", path: "/articles", expectedRoute: "/articles", expectedStatus: http.StatusOK, expectedLevel: "INFO", expectedRecords: 1},
+		{name: "unknown route", method: "GET", path: "/nowhere", expectedRoute: "", expectedStatus: http.StatusNotFound, expectedLevel: "INFO", expectedRecords: 1},
+		{name: "handler logger", method: "GET", path: "/echo", requestID: "req-2", expectedRoute: "/echo", expectedStatus: http.StatusOK, expectedLevel: "INFO", expectedRecords: 2, expectedRequestID: "req-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set(handlers.RequestIDHeader, tt.requestID)
+			}
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			id := w.Header().Get(handlers.RequestIDHeader)
+			require.NotEmpty(t, id)
+			if tt.expectedRequestID != "" {
+				assert.Equal(t, tt.expectedRequestID, id)
+			}
+
+			records := logRecords(t, &logs)
+			require.Len(t, records, tt.expectedRecords)
+			for _, record := range records {
+				assert.Equal(t, id, record["request_id"], "every record of the request carries its id")
+			}
+
+			access := records[len(records)-1]
+			assert.Equal(t, "request", access["msg"])
+			assert.Equal(t, tt.expectedLevel, access["level"])
+			assert.Equal(t, tt.method, access["method"])
+			assert.Equal(t, tt.expectedRoute, access["route"])
+			assert.Equal(t, tt.path, access["path"])
+			assert.EqualValues(t, tt.expectedStatus, access["status"])
+			assert.EqualValues(t, w.Body.Len(), access["bytes"])
+			assert.Contains(t, access, "latency")
+		})
+	}
+}
+
+func TestRequestLoggerPanicStack(t *testing.T) {
+	var logs bytes.Buffer
+	cfg := routerConfig
+	cfg.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+	r := newRouter(setupTestDB(t), cfg)
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, handlers.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.NotContains(t, w.Body.String(), "boom", "the panic is not shown to the client")
+
+	records := logRecords(t, &logs)
+	require.Len(t, records, 2)
+	assert.Equal(t, "ERROR", records[1]["level"])
+	assert.Equal(t, "boom", records[0]["panic"])
+	assert.Contains(t, records[0]["stack"], "runtime/debug.Stack")
+	assert.Equal(t, "panic: boom", records[1]["error"])
+}
+
+func TestLoggingNew(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           logging.Options
+		expectedOutput string
+		expectedError  string
+	}{
+		{name: "text", opts: logging.Options{Level: "info", Format: logging.FormatText}, expectedOutput: `level=INFO msg=hello key=value`},
+		{name: "json", opts: logging.Options{Level: "INFO", Format: logging.FormatJSON}, expectedOutput: `"level":"INFO","msg":"hello","key":"value"`},
+		{name: "level filters", opts: logging.Options{Level: "warn", Format: logging.FormatText}, expectedOutput: ""},
+		{name: "unknown level", opts: logging.Options{Level: "verbose", Format: logging.FormatText}, expectedError: "unknown log level: verbose"},
+		{name: "unknown format", opts: logging.Options{Level: "info", Format: "xml"}, expectedError: "unknown log format: xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger, err := logging.New(&out, tt.opts)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			logger.Info("hello", "key", "value")
+			assert.Contains(t, out.String(), tt.expectedOutput)
+			if tt.expectedOutput == "" {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hexlet-components/go-gin-example/cmd"
+	migrate "github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/hexlet-components/go-gin-example/internal/metrics"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrape returns what GET /metrics of r serves
+func scrape(t *testing.T, r http.Handler) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	testDB := setupTestDB(t)
+	m := metrics.New()
+	m.RegisterPools(migrate.SinglePool(testDB))
+
+	cfg := routerConfig
+	cfg.DB = testDB
+	cfg.Metrics = m
+	cfg.MetricsRoute = true
+	r := handlers.SetupRouter(store.New(migrate.DialectSQLite, migrate.SinglePool(testDB), m.ObserveQuery), cfg)
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{"POST", "/articles", `{"name": "Метрики", "body": "Prometheus"}`},
+		{"GET", "/articles/1", ""},
+		{"GET", "/articles/998", ""},
+		{"GET", "/articles/999", ""},
+		{"GET", "/articles", ""},
+		{"GET", "/articles?status=published", ""},
+		{"GET", "/wp-login.php", ""},
+		{"BREW", "/articles", ""},
+		{"XYZ1", "/articles/1", ""},
+	}
+	for _, req := range requests {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(req.method, req.path, strings.NewReader(req.body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, httpReq)
+	}
+
+	body := scrape(t, r)
+
+	tests := []struct {
+		name           string
+		expectedSeries string
+	}{
+		{name: "created", expectedSeries: `http_requests_total{method="POST",route="/articles",status="2xx"} 1`},
+		{name: "found", expectedSeries: `http_requests_total{method="GET",route="/articles/:id",status="2xx"} 1`},
+		{name: "not found by template", expectedSeries: `http_requests_total{method="GET",route="/articles/:id",status="4xx"} 2`},
+		{name: "unmatched", expectedSeries: `http_requests_total{method="GET",route="unmatched",status="4xx"} 1`},
+		{name: "unknown methods", expectedSeries: `http_requests_total{method="other",route="unmatched",status="4xx"} 2`},
+		{name: "latency", expectedSeries: `http_request_duration_seconds_count{method="GET",route="/articles",status="2xx"} 2`},
+		{name: "scrape in flight", expectedSeries: `http_requests_in_flight 1`},
+		{name: "sqlc query", expectedSeries: `db_query_duration_seconds_count{query="GetArticle"} 3`},
+		{name: "query in transaction", expectedSeries: `db_query_duration_seconds_count{query="CreateArticle"} 1`},
+		{name: "hand-written query", expectedSeries: `db_query_duration_seconds_count{query="FindArticles"} 1`},
+		{name: "pool", expectedSeries: `go_sql_max_open_connections{db_name="main"} 1`},
+		{name: "build info", expectedSeries: `go_gin_example_build_info{commit=`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, body, tt.expectedSeries)
+		})
+	}
+
+	assert.NotContains(t, body, `route="/articles/1"`, "raw paths would make a series per article")
+	assert.NotContains(t, body, `query="unnamed"`)
+	assert.NotContains(t, body, `method="BREW"`, "raw methods would make a series per method")
+}
+
+func TestMetricsRouteDisabled(t *testing.T) {
+	cfg := routerConfig
+	cfg.Metrics = metrics.New()
+	r := newRouter(setupTestDB(t), cfg)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "the metrics are left to the admin server")
+}
+
+// freePort returns a port nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+	return port
+}
+
+func TestServeMetricsPort(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "app.db")
+	require.Equal(t, cmd.ExitOK, cmd.Run([]string{"migrate", "up", "--db", dbPath}, io.Discard, io.Discard))
+
+	port, metricsPort := freePort(t), freePort(t)
+	codes := make(chan int, 1)
+	var stderr bytes.Buffer
+	go func() {
+		codes <- cmd.Run([]string{"serve", "--db", dbPath, "--port", strconv.Itoa(port), "--metrics-port", strconv.Itoa(metricsPort)}, io.Discard, &stderr)
+	}()
+
+	get := func(port int, path string) int {
+		resp, err := http.Get("http://127.0.0.1:" + strconv.Itoa(port) + path)
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	require.Eventually(t, func() bool {
+		return get(port, "/articles") == http.StatusOK && get(metricsPort, "/metrics") == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, http.StatusNotFound, get(port, "/metrics"), "the API port does not expose the metrics")
+	assert.Equal(t, http.StatusNotFound, get(metricsPort, "/articles"), "the metrics port does not expose the API")
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	select {
+	case code := <-codes:
+		assert.Equal(t, cmd.ExitOK, code, stderr.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not stop on SIGTERM")
+	}
+	assert.Equal(t, 2, strings.Count(stderr.String(), "Server stopped"), "both servers shut down")
+}
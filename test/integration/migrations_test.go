@@ -0,0 +1,235 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/db/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaOf lists every table, index, trigger and view with its SQL, leaving
+// out the goose version table that the migrations do not own
+func schemaOf(t *testing.T, database *sql.DB) []string {
+	t.Helper()
+
+	rows, err := database.Query(`
+		SELECT type, name, coalesce(sql, '') FROM sqlite_master
+		WHERE name NOT LIKE 'sqlite_%' AND tbl_name != ?
+		ORDER BY type, name`, goose.DefaultTablename)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var schema []string
+	for rows.Next() {
+		var kind, name, sql string
+		require.NoError(t, rows.Scan(&kind, &name, &sql))
+		schema = append(schema, fmt.Sprintf("%s %s: %s", kind, name, sql))
+	}
+	require.NoError(t, rows.Err())
+	return schema
+}
+
+// TestMigrationsRoundTrip applies the migrations one by one and checks that
+// rolling each back restores the schema it started from, and that applying
+// it again gives the same schema as the first time
+func TestMigrationsRoundTrip(t *testing.T) {
+	goose.SetBaseFS(migrations.FS)
+	all, err := goose.CollectMigrations(".", 0, goose.MaxVersion)
+	require.NoError(t, err)
+
+	opts := db.DefaultMigrationOptions()
+	opts.DBFile = filepath.Join(t.TempDir(), "scratch.db")
+
+	database, err := sql.Open("sqlite3", opts.DBFile)
+	require.NoError(t, err)
+	defer database.Close()
+
+	previous := int64(0)
+	for _, m := range all {
+		name := filepath.Base(m.Source)
+		before := schemaOf(t, database)
+
+		require.NoError(t, db.MigrateUpTo(opts, m.Version), name)
+		after := schemaOf(t, database)
+
+		require.NoError(t, db.MigrateDown(opts), name)
+		assert.Equal(t, before, schemaOf(t, database), "%s: down does not undo up", name)
+
+		require.NoError(t, db.MigrateUpTo(opts, m.Version), name)
+		assert.Equal(t, after, schemaOf(t, database), "%s: up is not repeatable", name)
+
+		version, err := db.MigrateVersion(opts)
+		require.NoError(t, err)
+		require.Equal(t, m.Version, version)
+		require.Greater(t, m.Version, previous)
+		previous = m.Version
+	}
+}
+
+// TestMigrateEscapesPath checks that migrations, backups and restores open
+// a file whose path SQLite would otherwise take for URI syntax
+func TestMigrateEscapesPath(t *testing.T) {
+	opts := db.DefaultMigrationOptions()
+	opts.DBFile = filepath.Join(t.TempDir(), "50% #1?x", "scratch.db")
+	opts.Out = io.Discard
+	require.NoError(t, os.Mkdir(filepath.Dir(opts.DBFile), 0o755))
+	require.NoError(t, db.MigrateUp(opts))
+	require.FileExists(t, opts.DBFile)
+
+	backup, err := db.MigrateBackup(opts)
+	require.NoError(t, err)
+	assert.FileExists(t, backup)
+
+	require.NoError(t, db.MigrateTo(opts, 1))
+	version, err := db.MigrateVersion(opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	require.NoError(t, db.MigrateRestore(opts, backup))
+	version, err = db.MigrateVersion(opts)
+	require.NoError(t, err)
+	assert.Greater(t, version, int64(1), "restored")
+}
+
+// TestMigrateDownLeftByFTS5 rolls back a database whose search index a
+// binary with FTS5 built. Without the module in this binary the index is
+// written into the schema by hand, as SQLite cannot create it.
+func TestMigrateDownLeftByFTS5(t *testing.T) {
+	opts := db.DefaultMigrationOptions()
+	opts.DBFile = filepath.Join(t.TempDir(), "scratch.db")
+	opts.Out = io.Discard
+	require.NoError(t, db.MigrateUp(opts))
+
+	database, err := sql.Open("sqlite3", opts.DBFile)
+	require.NoError(t, err)
+	defer database.Close()
+
+	if !ftsAvailable(t, database) {
+		statements := []string{
+			`PRAGMA writable_schema = ON`,
+			`INSERT INTO sqlite_master (type, name, tbl_name, rootpage, sql)
+			VALUES ('table', 'articles_fts', 'articles_fts', 0, 'CREATE VIRTUAL TABLE articles_fts USING fts5(name, body)')`,
+			`PRAGMA writable_schema = OFF`,
+			`CREATE TRIGGER articles_fts_ai AFTER INSERT ON articles BEGIN
+				INSERT INTO articles_fts (rowid, name, body) VALUES (new.id, new.name, new.body);
+			END`,
+		}
+		for _, stmt := range statements {
+			_, err := database.Exec(stmt)
+			require.NoError(t, err, stmt)
+		}
+	}
+	require.NoError(t, database.Close())
+
+	require.NoError(t, db.MigrateDownTo(opts, 1))
+	version, err := db.MigrateVersion(opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	require.NoError(t, db.MigrateUp(opts), "the rollback left no partial migration")
+}
+
+func TestBackfillArticleSlugs(t *testing.T) {
+	opts := db.DefaultMigrationOptions()
+	opts.DBFile = filepath.Join(t.TempDir(), "scratch.db")
+	require.NoError(t, db.MigrateUpTo(opts, 10))
+
+	database, err := sql.Open("sqlite3", opts.DBFile)
+	require.NoError(t, err)
+	defer database.Close()
+
+	articles := []struct {
+		name string
+		slug any
+	}{
+		{name: "Hello World", slug: "hello-world"},
+		{name: "Hello, World!", slug: nil},
+		{name: "Привет мир", slug: nil},
+		{name: "Renamed", slug: nil},
+		{name: "Set by hand", slug: "custom"},
+	}
+	for _, a := range articles {
+		_, err := database.Exec(`INSERT INTO articles (name, name_key, slug) VALUES (?, ?, ?)`, a.name, a.name, a.slug)
+		require.NoError(t, err)
+	}
+	// A former slug of another article is taken as well
+	_, err = database.Exec(`INSERT INTO article_slugs (slug, article_id, created_at) VALUES ('renamed', 5, '2024-01-01 00:00:00+00:00')`)
+	require.NoError(t, err)
+
+	require.NoError(t, db.MigrateUpTo(opts, 11))
+
+	var slugs []string
+	rows, err := database.Query(`SELECT slug FROM articles ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var slug string
+		require.NoError(t, rows.Scan(&slug))
+		slugs = append(slugs, slug)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"hello-world", "hello-world-2", "privet-mir", "renamed-2", "custom"}, slugs)
+}
+
+// TestSyncSearchIndex checks that the search index follows the binary rather
+// than the schema version: a binary with FTS5 rebuilds an index whose
+// triggers are gone, one without it drops triggers it cannot run
+func TestSyncSearchIndex(t *testing.T) {
+	opts := db.DefaultMigrationOptions()
+	opts.DBFile = filepath.Join(t.TempDir(), "scratch.db")
+	require.NoError(t, db.MigrateUp(opts))
+
+	database, err := sql.Open("sqlite3", opts.DBFile)
+	require.NoError(t, err)
+	defer database.Close()
+
+	insert := func(name string) error {
+		_, err := database.Exec(`INSERT INTO articles (name, name_key, body) VALUES (?, ?, 'text')`, name, name)
+		return err
+	}
+
+	if ftsAvailable(t, database) {
+		// As a binary without FTS5 leaves it
+		for _, trigger := range []string{"articles_fts_ai", "articles_fts_ad", "articles_fts_au"} {
+			_, err := database.Exec(`DROP TRIGGER ` + trigger)
+			require.NoError(t, err)
+		}
+		require.NoError(t, insert("Unindexed"))
+	} else {
+		// As a binary with FTS5 leaves it
+		_, err := database.Exec(`CREATE TRIGGER articles_fts_ai AFTER INSERT ON articles BEGIN
+			INSERT INTO articles_fts (rowid, name) VALUES (new.id, new.name);
+		END`)
+		require.NoError(t, err)
+		require.ErrorContains(t, insert("Unindexed"), "articles_fts")
+	}
+
+	for range 2 {
+		require.NoError(t, db.SyncSearchIndex(context.Background(), database, db.DialectSQLite))
+	}
+	require.NoError(t, insert("Indexed"))
+
+	if !ftsAvailable(t, database) {
+		return
+	}
+	var ids []int64
+	rows, err := database.Query(`SELECT rowid FROM articles_fts WHERE articles_fts MATCH 'indexed OR unindexed' ORDER BY rowid`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int64{1, 2}, ids)
+}
@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type articlePage struct {
+	Items      []db.Article `json:"items"`
+	NextCursor *string      `json:"next_cursor"`
+}
+
+func seedArticles(t *testing.T, queries *db.Queries, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		_, err := queries.CreateArticle(context.Background(), articleParams(fmt.Sprintf("Article %d", i)))
+		if err != nil {
+			t.Fatalf("failed to create test article: %v", err)
+		}
+	}
+}
+
+func getPage(t *testing.T, router http.Handler, url string) (*httptest.ResponseRecorder, articlePage) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var page articlePage
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	}
+	return w, page
+}
+
+func ids(articles []db.Article) []int64 {
+	result := make([]int64, 0, len(articles))
+	for _, a := range articles {
+		result = append(result, a.ID)
+	}
+	return result
+}
+
+func TestListArticlesOffsetPagination(t *testing.T) {
+	router, queries := setupTestRouterWithQueries(t)
+	seedArticles(t, queries, 5)
+
+	w, page := getPage(t, router, "/articles?limit=2&offset=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{3, 4}, ids(page.Items))
+	assert.NotNil(t, page.NextCursor)
+	assert.Equal(t,
+		`</articles?limit=2>; rel="first", </articles?limit=2&offset=0>; rel="prev", </articles?limit=2&offset=4>; rel="next"`,
+		w.Header().Get("Link"))
+
+	w, page = getPage(t, router, "/articles?limit=2&offset=4")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{5}, ids(page.Items))
+	assert.Nil(t, page.NextCursor)
+	assert.NotContains(t, w.Header().Get("Link"), `rel="next"`)
+}
+
+func TestListArticlesCursorPagination(t *testing.T) {
+	router, queries := setupTestRouterWithQueries(t)
+	seedArticles(t, queries, 5)
+
+	var seen []int64
+	url := "/articles?limit=2"
+	for range 5 {
+		w, page := getPage(t, router, url)
+		require.Equal(t, http.StatusOK, w.Code)
+		seen = append(seen, ids(page.Items)...)
+		if page.NextCursor == nil {
+			break
+		}
+		assert.Contains(t, w.Header().Get("Link"), `rel="next"`)
+		url = "/articles?limit=2&after=" + *page.NextCursor
+	}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, seen)
+}
+
+func TestListArticlesPaginationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "limit too large", url: "/articles?limit=1000"},
+		{name: "limit zero", url: "/articles?limit=0"},
+		{name: "limit not a number", url: "/articles?limit=abc"},
+		{name: "negative offset", url: "/articles?offset=-1"},
+		{name: "malformed cursor", url: "/articles?after=!!!"},
+		{name: "offset and cursor together", url: "/articles?offset=1&after=eyJpZCI6MX0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+
+			w, _ := getPage(t, router, tt.url)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
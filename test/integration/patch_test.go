@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchArticle(t *testing.T, router http.Handler, url, contentType, body string) (*httptest.ResponseRecorder, db.Article) {
+	t.Helper()
+	req, _ := http.NewRequest("PATCH", url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var article db.Article
+	if w.Code < http.StatusBadRequest {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &article))
+	}
+	return w, article
+}
+
+func TestPatchArticle(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedType   string
+	}{
+		{
+			name:           "merge patch changes listed fields",
+			contentType:    handlers.MergePatchContentType,
+			body:           `{"body":"Новый текст","status":"published"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":"Test Article","body":"Новый текст","slug":"test-article","status":"published","created_at":"<time>","updated_at":"<time>","published_at":"<time>","version":2,"deleted_at":null}`,
+		},
+		{
+			name:           "merge patch of name regenerates slug",
+			contentType:    handlers.MergePatchContentType,
+			body:           `{"name":"Renamed"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":"Renamed","body":"Text","slug":"renamed","status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":2,"deleted_at":null}`,
+		},
+		{
+			name:           "merge patch with charset parameter",
+			contentType:    handlers.MergePatchContentType + "; charset=utf-8",
+			body:           `{"body":""}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":"Test Article","body":"","slug":"test-article","status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":2,"deleted_at":null}`,
+		},
+		{
+			name:           "json patch",
+			contentType:    handlers.JSONPatchContentType,
+			body:           `[{"op":"test","path":"/status","value":"draft"},{"op":"replace","path":"/slug","value":"custom"}]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":"Test Article","body":"Text","slug":"custom","status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":2,"deleted_at":null}`,
+		},
+		{
+			name:           "json patch failed test",
+			contentType:    handlers.JSONPatchContentType,
+			body:           `[{"op":"test","path":"/status","value":"published"}]`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedType:   "patch-failed",
+		},
+		{
+			name:           "json patch unknown path",
+			contentType:    handlers.JSONPatchContentType,
+			body:           `[{"op":"replace","path":"/missing/0","value":1}]`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedType:   "patch-failed",
+		},
+		{
+			name:           "malformed json patch",
+			contentType:    handlers.JSONPatchContentType,
+			body:           `{"op":"replace"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "invalid-patch",
+		},
+		{
+			name:           "malformed merge patch",
+			contentType:    handlers.MergePatchContentType,
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "invalid-patch",
+		},
+		{
+			name:           "merge patch removing name",
+			contentType:    handlers.MergePatchContentType,
+			body:           `{"name":null}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "validation-failed",
+		},
+		{
+			name:           "merge patch with invalid status",
+			contentType:    handlers.MergePatchContentType,
+			body:           `{"status":"deleted"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "validation-failed",
+		},
+		{
+			name:           "merge patch with blank name",
+			contentType:    handlers.MergePatchContentType,
+			body:           `{"name":"   "}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "name-empty",
+		},
+		{
+			name:           "plain json",
+			contentType:    "application/json",
+			body:           `{"name":"Renamed"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedType:   "unsupported-patch-type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+			w, _ := sendArticle(t, router, "POST", "/articles", `{"name":"Test Article","body":"Text"}`)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			w, _ = patchArticle(t, router, "/articles/1", tt.contentType, tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assertArticleJSON(t, tt.expectedBody, w.Body.String())
+			}
+			if tt.expectedType != "" {
+				var p handlers.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+				assert.Equal(t, handlers.ProblemTypeBase+tt.expectedType, p.Type)
+			}
+		})
+	}
+}
+
+func TestPatchArticleNotFound(t *testing.T) {
+	router := setupTestRouter(t)
+
+	w, _ := patchArticle(t, router, "/articles/42", handlers.MergePatchContentType, `{"name":"Nope"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = patchArticle(t, router, "/articles/abc", handlers.MergePatchContentType, `{"name":"Nope"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPatchArticleUnchangedColumns(t *testing.T) {
+	router := setupTestRouter(t)
+
+	_, article := sendArticle(t, router, "POST", "/articles", `{"name":"Test Article","body":"Text"}`)
+
+	w, same := patchArticle(t, router, "/articles/1", handlers.MergePatchContentType, `{"name":"Test Article"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, article.UpdatedAt, same.UpdatedAt, "a no-op patch does not touch the row")
+
+	w, _ = sendArticle(t, router, "POST", "/articles", `{"name":"Other"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w, _ = patchArticle(t, router, "/articles/1", handlers.MergePatchContentType, `{"name":"OTHER"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, renamed := patchArticle(t, router, "/articles/1", handlers.MergePatchContentType, `{"name":"Renamed"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "renamed", *renamed.Slug)
+
+	req, _ := http.NewRequest("GET", "/articles/by-slug/test-article", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code, "the old slug redirects")
+}
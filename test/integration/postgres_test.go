@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	migrate "github.com/hexlet-components/go-gin-example/db"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPostgres migrates the database at $TEST_POSTGRES_DSN from scratch.
+// The tests that use it share that database, so they are skipped unless it
+// is set and must not run in parallel.
+func setupPostgres(t *testing.T) (*sql.DB, *migrate.MigrationOptions) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	opts := migrate.PostgresMigrationOptions(dsn)
+	opts.Out = io.Discard
+	require.NoError(t, migrate.MigrateDownTo(opts, 0))
+	require.NoError(t, migrate.MigrateUp(opts))
+
+	database, err := sql.Open(migrate.DriverName(migrate.DialectPostgres), dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+	return database, opts
+}
+
+func TestPostgresMigrations(t *testing.T) {
+	database, opts := setupPostgres(t)
+
+	versions, err := migrate.MigrationVersions(opts)
+	require.NoError(t, err)
+	status, err := migrate.CheckSchema(t.Context(), database, migrate.DialectPostgres, versions)
+	require.NoError(t, err)
+	assert.True(t, status.UpToDate())
+	assert.Equal(t, versions[len(versions)-1], status.Version)
+
+	require.NoError(t, migrate.MigrateDownTo(opts, 0))
+	version, err := migrate.MigrateVersion(opts)
+	require.NoError(t, err)
+	assert.Zero(t, version)
+
+	require.NoError(t, migrate.MigrateUp(opts))
+	version, err = migrate.MigrateVersion(opts)
+	require.NoError(t, err)
+	assert.Equal(t, status.Version, version)
+}
+
+func TestPostgresArticles(t *testing.T) {
+	database, _ := setupPostgres(t)
+	router := handlers.SetupRouter(store.NewPostgres(database), handlers.RouterConfig{
+		DB:         database,
+		Dialect:    migrate.DialectPostgres,
+		AdminToken: adminToken,
+	})
+
+	w, article := sendArticle(t, router, "POST", "/articles", `{"name":"Введение в Go","body":"Горутины и каналы"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "vvedenie-v-go", *article.Slug)
+
+	w, _ = sendArticle(t, router, "POST", "/articles", `{"name":"ВВЕДЕНИЕ В GO"}`)
+	require.Equal(t, http.StatusConflict, w.Code)
+	var problem handlers.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "name", problem.Field)
+
+	w, _ = sendArticle(t, router, "POST", "/articles", `{"name":"Ёлка на Rust","body":"Аллокаторы"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w, renamed := sendArticle(t, router, "PUT", "/articles/1", `{"name":"Основы Go","body":"Горутины и каналы","status":"published"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int64(2), renamed.Version)
+	require.NotNil(t, renamed.PublishedAt)
+
+	tests := []struct {
+		name        string
+		url         string
+		expectedIDs []int64
+	}{
+		{name: "contains is case-insensitive", url: "/articles?name[contains]=go", expectedIDs: []int64{1}},
+		{name: "sorted cursor page", url: "/articles?sort=-id&limit=1", expectedIDs: []int64{2}},
+		{name: "filter by status", url: "/articles?status=published", expectedIDs: []int64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, page := getPage(t, router, tt.url)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, tt.expectedIDs, ids(page.Items))
+		})
+	}
+
+	req, _ := http.NewRequest("GET", "/articles/by-slug/vvedenie-v-go", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/articles/by-slug/osnovy-go", w.Header().Get("Location"))
+
+	w, page := search(t, router, "/articles/search?q=елка")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, int64(2), page.Items[0].ID)
+	assert.Contains(t, page.Items[0].Snippet, "<mark>Елка</mark>")
+
+	_, page = search(t, router, "/articles/search?q=горут")
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, int64(1), page.Items[0].ID)
+
+	w = deleteArticle(router, "/articles/2", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	_, page = search(t, router, "/articles/search?q=rust")
+	assert.Empty(t, page.Items)
+
+	w, trash := getPage(t, router, "/articles/trash")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{2}, ids(trash.Items))
+}
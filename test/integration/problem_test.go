@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetails(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedType   string
+		expectedTitle  string
+	}{
+		{
+			name:           "invalid id",
+			method:         "GET",
+			url:            "/articles/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   handlers.ProblemTypeBase + "invalid-id",
+			expectedTitle:  "Invalid article id",
+		},
+		{
+			name:           "article not found",
+			method:         "GET",
+			url:            "/articles/999",
+			expectedStatus: http.StatusNotFound,
+			expectedType:   handlers.ProblemTypeBase + "article-not-found",
+			expectedTitle:  "Article not found",
+		},
+		{
+			name:           "blank name",
+			method:         "POST",
+			url:            "/articles",
+			body:           `{"name":"   "}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   handlers.ProblemTypeBase + "name-empty",
+			expectedTitle:  "Article name is empty",
+		},
+		{
+			name:           "wrapped sentinel",
+			method:         "GET",
+			url:            "/articles?sort=title",
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   handlers.ProblemTypeBase + "unknown-field",
+			expectedTitle:  "Unknown field",
+		},
+		{
+			name:           "unregistered error",
+			method:         "POST",
+			url:            "/articles",
+			body:           `{"name":}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "about:blank",
+			expectedTitle:  "Bad Request",
+		},
+		{
+			name:           "unknown route",
+			method:         "GET",
+			url:            "/nope",
+			expectedStatus: http.StatusNotFound,
+			expectedType:   handlers.ProblemTypeBase + "route-not-found",
+			expectedTitle:  "Route not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+
+			req, _ := http.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, handlers.ProblemContentType, w.Header().Get("Content-Type"))
+
+			var problem handlers.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedType, problem.Type)
+			assert.Equal(t, tt.expectedTitle, problem.Title)
+			assert.Equal(t, tt.expectedStatus, problem.Status)
+			assert.NotEmpty(t, problem.Detail)
+			assert.Equal(t, req.URL.Path, problem.Instance)
+			assert.NotEmpty(t, problem.RequestID)
+			assert.Equal(t, problem.RequestID, w.Header().Get(handlers.RequestIDHeader))
+		})
+	}
+}
+
+func TestProblemDetailsPropagatesRequestID(t *testing.T) {
+	router := setupTestRouter(t)
+
+	req, _ := http.NewRequest("GET", "/articles/999", nil)
+	req.Header.Set(handlers.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var problem handlers.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "req-42", problem.RequestID)
+	assert.Equal(t, "req-42", w.Header().Get(handlers.RequestIDHeader))
+}
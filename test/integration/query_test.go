@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListArticlesFilterAndSort(t *testing.T) {
+	names := []string{"Go basics", "Rust basics", "Advanced Go", "Gin routing", "100%_done"}
+
+	tests := []struct {
+		name        string
+		url         string
+		expectedIDs []int64
+	}{
+		{name: "sort by name descending", url: "/articles?sort=-name", expectedIDs: []int64{2, 1, 4, 3, 5}},
+		{name: "sort by name then id", url: "/articles?sort=name,-id", expectedIDs: []int64{5, 3, 4, 1, 2}},
+		{name: "name contains", url: "/articles?name[contains]=Go", expectedIDs: []int64{1, 3}},
+		{name: "name prefix", url: "/articles?name[prefix]=G", expectedIDs: []int64{1, 4}},
+		{name: "like wildcards are escaped", url: "/articles?name[contains]=%25_", expectedIDs: []int64{5}},
+		{name: "name equals shorthand", url: "/articles?name=Gin%20routing", expectedIDs: []int64{4}},
+		{name: "id greater than", url: "/articles?id[gt]=3", expectedIDs: []int64{4, 5}},
+		{name: "id in", url: "/articles?id[in]=1,3,5&sort=-id", expectedIDs: []int64{5, 3, 1}},
+		{name: "combined filters", url: "/articles?name[contains]=basics&id[gte]=2", expectedIDs: []int64{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, queries := setupTestRouterWithQueries(t)
+			for _, name := range names {
+				_, err := queries.CreateArticle(context.Background(), articleParams(name))
+				require.NoError(t, err)
+			}
+
+			w, page := getPage(t, router, tt.url)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedIDs, ids(page.Items))
+		})
+	}
+}
+
+func TestListArticlesSortedCursorPagination(t *testing.T) {
+	router, queries := setupTestRouterWithQueries(t)
+	for _, name := range []string{"b", "a", "c", "e", "d"} {
+		_, err := queries.CreateArticle(context.Background(), articleParams(name))
+		require.NoError(t, err)
+	}
+
+	var seen []int64
+	url := "/articles?sort=-name&limit=2"
+	for range 5 {
+		w, page := getPage(t, router, url)
+		require.Equal(t, http.StatusOK, w.Code)
+		seen = append(seen, ids(page.Items)...)
+		if page.NextCursor == nil {
+			break
+		}
+		url = "/articles?sort=-name&limit=2&after=" + *page.NextCursor
+	}
+
+	assert.Equal(t, []int64{4, 5, 3, 1, 2}, seen)
+
+	_, page := getPage(t, router, "/articles?sort=-name&limit=2")
+	w, _ := getPage(t, router, "/articles?sort=name&after="+*page.NextCursor)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListArticlesQueryErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedParam string
+	}{
+		{name: "unknown sort field", url: "/articles?sort=title", expectedParam: "sort"},
+		{name: "duplicate sort field", url: "/articles?sort=name,-name", expectedParam: "sort"},
+		{name: "unknown filter field", url: "/articles?title[eq]=x", expectedParam: "title[eq]"},
+		{name: "unknown operator", url: "/articles?name[regex]=x", expectedParam: "name[regex]"},
+		{name: "operator not allowed for field", url: "/articles?id[contains]=1", expectedParam: "id[contains]"},
+		{name: "invalid integer value", url: "/articles?id[gt]=abc", expectedParam: "id[gt]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedParam, body["param"])
+		})
+	}
+}
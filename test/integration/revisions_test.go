@@ -0,0 +1,195 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type revisionPage struct {
+	Items []handlers.Revision `json:"items"`
+}
+
+func revisionNumbers(revisions []handlers.Revision) []int64 {
+	result := make([]int64, 0, len(revisions))
+	for _, r := range revisions {
+		result = append(result, r.Rev)
+	}
+	return result
+}
+
+// seedRevisions creates an article and edits it twice, so it has revisions
+// 1 and 2 and is at version 3
+func seedRevisions(t *testing.T, router http.Handler) {
+	t.Helper()
+	for _, step := range []struct{ method, url, body string }{
+		{"POST", "/articles", `{"name":"Draft","body":"one\ntwo\nthree"}`},
+		{"PUT", "/articles/1", `{"name":"Draft","body":"one\n2\nthree\nfour","status":"published"}`},
+		{"PUT", "/articles/1", `{"name":"Final","body":"one\n2\nthree\nfour","status":"published"}`},
+	} {
+		w, _ := sendArticle(t, router, step.method, step.url, step.body)
+		require.Less(t, w.Code, http.StatusBadRequest, step.body)
+	}
+}
+
+func TestListRevisions(t *testing.T) {
+	router := setupTestRouter(t)
+	seedRevisions(t, router)
+
+	tests := []struct {
+		name         string
+		url          string
+		expectedRevs []int64
+		expectedNext bool
+	}{
+		{name: "all revisions newest first", url: "/articles/1/revisions", expectedRevs: []int64{3, 2, 1}},
+		{name: "first page", url: "/articles/1/revisions?limit=2", expectedRevs: []int64{3, 2}, expectedNext: true},
+		{name: "second page", url: "/articles/1/revisions?limit=2&offset=2", expectedRevs: []int64{1}},
+		{name: "skipping the current state", url: "/articles/1/revisions?offset=1", expectedRevs: []int64{2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := sendArticle(t, router, "GET", tt.url, "")
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var page revisionPage
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			assert.Equal(t, tt.expectedRevs, revisionNumbers(page.Items))
+			assert.Equal(t, tt.expectedNext, strings.Contains(w.Header().Get("Link"), `rel="next"`))
+		})
+	}
+
+	w, _ := sendArticle(t, router, "GET", "/articles/42/revisions", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetRevision(t *testing.T) {
+	router := setupTestRouter(t)
+	seedRevisions(t, router)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "saved revision",
+			url:            "/articles/1/revisions/1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"article_id":1,"rev":1,"name":"Draft","body":"one\ntwo\nthree","slug":"draft","status":"draft","created_at":"<time>","current":false}`,
+		},
+		{
+			name:           "current revision",
+			url:            "/articles/1/revisions/3",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"article_id":1,"rev":3,"name":"Final","body":"one\n2\nthree\nfour","slug":"final","status":"published","created_at":"<time>","current":true}`,
+		},
+		{name: "unknown revision", url: "/articles/1/revisions/9", expectedStatus: http.StatusNotFound},
+		{name: "invalid revision", url: "/articles/1/revisions/abc", expectedStatus: http.StatusBadRequest},
+		{name: "missing article", url: "/articles/42/revisions/1", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := sendArticle(t, router, "GET", tt.url, "")
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assertArticleJSON(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDiffRevisions(t *testing.T) {
+	router := setupTestRouter(t)
+	seedRevisions(t, router)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "against the current state",
+			url:            "/articles/1/revisions/1/diff",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"from":1,"to":3,
+				"fields":{"name":{"from":"Draft","to":"Final"},"slug":{"from":"draft","to":"final"},"status":{"from":"draft","to":"published"}},
+				"body":[{"op":"equal","text":"one"},{"op":"delete","text":"two"},{"op":"insert","text":"2"},{"op":"equal","text":"three"},{"op":"insert","text":"four"}]}`,
+		},
+		{
+			name:           "between saved revisions backwards",
+			url:            "/articles/1/revisions/2/diff?to=1",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"from":2,"to":1,
+				"fields":{"status":{"from":"published","to":"draft"}},
+				"body":[{"op":"equal","text":"one"},{"op":"delete","text":"2"},{"op":"insert","text":"two"},{"op":"equal","text":"three"},{"op":"delete","text":"four"}]}`,
+		},
+		{
+			name:           "identical revisions",
+			url:            "/articles/1/revisions/2/diff?to=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"from":2,"to":2,"fields":{},"body":[{"op":"equal","text":"one"},{"op":"equal","text":"2"},{"op":"equal","text":"three"},{"op":"equal","text":"four"}]}`,
+		},
+		{name: "unknown target", url: "/articles/1/revisions/1/diff?to=9", expectedStatus: http.StatusNotFound},
+		{name: "invalid target", url: "/articles/1/revisions/1/diff?to=x", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := conditionalRequest(router, "GET", tt.url, "", "", "", "")
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRevertRevision(t *testing.T) {
+	router := setupTestRouter(t)
+	seedRevisions(t, router)
+
+	w, article := sendArticle(t, router, "POST", "/articles/1/revisions/1/revert", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assertArticleJSON(t,
+		`{"id":1,"name":"Draft","body":"one\ntwo\nthree","slug":"draft","status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":4,"deleted_at":null}`,
+		w.Body.String())
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	assert.Equal(t, "draft", *article.Slug)
+
+	// The reverted-from state is kept as a revision too
+	w, _ = sendArticle(t, router, "GET", "/articles/1/revisions/3", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Final"`)
+
+	w = conditionalRequest(router, "GET", "/articles/by-slug/final", "", "", "", "")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+
+	w, _ = sendArticle(t, router, "POST", "/articles/1/revisions/9/revert", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = conditionalRequest(router, "POST", "/articles/1/revisions/2/revert", "If-Match", `"1"`, "", "")
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestPatchSavesRevision(t *testing.T) {
+	router := setupTestRouter(t)
+	w, _ := sendArticle(t, router, "POST", "/articles", `{"name":"Draft","body":"old"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = conditionalRequest(router, "PATCH", "/articles/1", "", "", handlers.MergePatchContentType, `{"body":"new"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w, _ = sendArticle(t, router, "GET", "/articles/1/revisions/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"body":"old"`)
+}
@@ -0,0 +1,130 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type searchPage struct {
+	Items []handlers.SearchResult `json:"items"`
+}
+
+func ftsAvailable(t *testing.T, database *sql.DB) bool {
+	t.Helper()
+	var n int
+	err := database.QueryRow(`SELECT count(*) FROM sqlite_master WHERE name = 'articles_fts'`).Scan(&n)
+	require.NoError(t, err)
+	return n > 0
+}
+
+func search(t *testing.T, router http.Handler, url string) (*httptest.ResponseRecorder, searchPage) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var page searchPage
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	}
+	return w, page
+}
+
+func TestSearchArticles(t *testing.T) {
+	testDB := setupTestDB(t)
+	router := newRouter(testDB, routerConfig)
+	if !ftsAvailable(t, testDB) {
+		w, _ := search(t, router, "/articles/search?q=go")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+		t.Skip("FTS5 is not compiled in, run tests with -tags sqlite_fts5")
+	}
+
+	queries := db.New(testDB)
+	for _, name := range []string{
+		"Введение в Go",
+		"Ёлка и горутины",
+		"Go, Go и ещё раз Go",
+		"Rust для начинающих",
+	} {
+		_, err := queries.CreateArticle(context.Background(), articleParams(name))
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		expectedIDs []int64
+	}{
+		{name: "latin word ranked by relevance", url: "/articles/search?q=go", expectedIDs: []int64{3, 1}},
+		{name: "cyrillic is case-insensitive", url: "/articles/search?q=ВВЕДЕНИЕ", expectedIDs: []int64{1}},
+		{name: "yo matches ye", url: "/articles/search?q=елка", expectedIDs: []int64{2}},
+		{name: "ye matches yo", url: "/articles/search?q=ещё", expectedIDs: []int64{3}},
+		{name: "last word matches as prefix", url: "/articles/search?q=горут", expectedIDs: []int64{2}},
+		{name: "fts syntax is treated as text", url: `/articles/search?q="NEAR(`, expectedIDs: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, page := search(t, router, tt.url)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			got := []int64{}
+			for _, r := range page.Items {
+				got = append(got, r.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, got)
+		})
+	}
+
+	_, page := search(t, router, "/articles/search?q=rust")
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "<mark>Rust</mark> для начинающих", page.Items[0].Snippet)
+	assert.Positive(t, page.Items[0].Rank)
+
+	_, err := queries.UpdateArticle(context.Background(), db.UpdateArticleParams{
+		ID:      4,
+		Name:    "Zig для начинающих",
+		NameKey: "zig для начинающих",
+		Body:    "Сравниваем аллокаторы и comptime",
+		Status:  "draft",
+		Now:     time.Now().UTC(),
+		Version: 1,
+	})
+	require.NoError(t, err)
+	_, page = search(t, router, "/articles/search?q=rust")
+	assert.Empty(t, page.Items)
+
+	_, page = search(t, router, "/articles/search?q=аллокаторы")
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Сравниваем <mark>аллокаторы</mark> и comptime", page.Items[0].Snippet)
+}
+
+func TestSearchArticlesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "missing query", url: "/articles/search"},
+		{name: "blank query", url: "/articles/search?q=%20%20"},
+		{name: "cursor pagination", url: "/articles/search?q=go&after=eyJpZCI6MX0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+
+			w, _ := search(t, router, tt.url)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hexlet-components/go-gin-example/cmd"
+	"github.com/hexlet-components/go-gin-example/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer serves handler on a free port until the returned context is
+// cancelled, Serve's result arrives on the channel
+func startServer(t *testing.T, handler http.Handler, opts server.Options) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, ln, handler, opts)
+	}()
+	t.Cleanup(cancel)
+	return "http://" + ln.Addr().String(), cancel, done
+}
+
+// slowHandler signals started when a request comes in and answers once
+// release is closed
+func slowHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		io.WriteString(w, "done")
+	})
+}
+
+func TestServerDrainsInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	url, shutdown, done := startServer(t, slowHandler(started, release), server.DefaultOptions())
+
+	type response struct {
+		status int
+		body   string
+		err    error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- response{status: resp.StatusCode, body: string(body)}
+	}()
+
+	<-started
+	shutdown()
+
+	// New connections are refused while the request in flight finishes
+	require.Eventually(t, func() bool {
+		_, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("server stopped before the request finished: %v", err)
+	default:
+	}
+
+	close(release)
+	resp := <-responses
+	require.NoError(t, resp.err)
+	assert.Equal(t, http.StatusOK, resp.status)
+	assert.Equal(t, "done", resp.body)
+	assert.NoError(t, <-done)
+}
+
+func TestServerShutdownTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	opts := server.DefaultOptions()
+	opts.ShutdownTimeout = 50 * time.Millisecond
+	url, shutdown, done := startServer(t, slowHandler(started, release), opts)
+
+	go http.Get(url)
+	<-started
+	shutdown()
+
+	err := <-done
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "did not finish in 50ms")
+}
+
+func TestServerLimits(t *testing.T) {
+	opts := server.DefaultOptions()
+	opts.MaxHeaderBytes = 1024
+	opts.ReadHeaderTimeout = 100 * time.Millisecond
+	url, _, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), opts)
+
+	t.Run("headers too large", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("X-Padding", strings.Repeat("a", 8192))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+	})
+
+	t.Run("silent client is disconnected", func(t *testing.T) {
+		conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, err = conn.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.EOF, "the server closes the connection without waiting for the read deadline")
+	})
+}
+
+func TestServerOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*server.Options)
+		expectedError string
+	}{
+		{name: "defaults", modify: func(*server.Options) {}},
+		{name: "timeouts disabled", modify: func(o *server.Options) { o.ReadTimeout, o.WriteTimeout = 0, 0 }},
+		{name: "negative timeout", modify: func(o *server.Options) { o.IdleTimeout = -time.Second }, expectedError: "idle timeout cannot be negative"},
+		{name: "no header room", modify: func(o *server.Options) { o.MaxHeaderBytes = 0 }, expectedError: "max header bytes must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := server.DefaultOptions()
+			tt.modify(&opts)
+			err := opts.Validate()
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+// TestServeStopsOnSignal runs the serve command and sends the process
+// SIGTERM, which the command takes over from the default of exiting
+func TestServeStopsOnSignal(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "app.db")
+	database, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	require.NoError(t, applyMigrations(database))
+	require.NoError(t, database.Close())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	codes := make(chan int, 1)
+	var stderr bytes.Buffer
+	go func() {
+		codes <- cmd.Run([]string{"serve", "--db", dbPath, "--port", strconv.Itoa(port), "--log-format", "json"}, io.Discard, &stderr)
+	}()
+
+	url := "http://127.0.0.1:" + strconv.Itoa(port) + "/articles"
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	select {
+	case code := <-codes:
+		assert.Equal(t, cmd.ExitOK, code, stderr.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not stop on SIGTERM")
+	}
+
+	var messages []string
+	for _, record := range logRecords(t, &stderr) {
+		messages = append(messages, record["msg"].(string))
+	}
+	assert.Contains(t, messages, "request", "the access log goes to the configured logger")
+	assert.Contains(t, messages, "Shutting down, waiting for in-flight requests")
+	assert.Contains(t, messages, "Server stopped, all requests finished")
+	assert.Equal(t, "Database closed", messages[len(messages)-1])
+}
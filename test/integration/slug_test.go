@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Привет, мир!", expected: "privet-mir"},
+		{name: "Съешь же ещё этих мягких французских булок", expected: "sesh-zhe-eshhyo-etix-myagkix-franczuzskix-bulok"},
+		{name: "Цапля и цирк", expected: "czaplya-i-cirk"},
+		{name: "Йога и щи", expected: "joga-i-shhi"},
+		{name: "Go 1.26: что нового", expected: "go-1-26-chto-novogo"},
+		{name: "Café déjà vu", expected: "cafe-deja-vu"},
+		{name: "  --Много   пробелов--  ", expected: "mnogo-probelov"},
+		{name: "Їжак і ґанок", expected: "yizhak-i-ganok"},
+		{name: "!!!", expected: textnorm.DefaultSlug},
+		{name: strings.Repeat("слово ", 40), expected: strings.TrimSuffix(strings.Repeat("slovo-", 16), "-")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, textnorm.Slugify(tt.name))
+		})
+	}
+}
+
+func TestArticleSlugGeneration(t *testing.T) {
+	router := setupTestRouter(t)
+
+	w, first := sendArticle(t, router, "POST", "/articles", `{"name":"Привет, мир"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "privet-mir", *first.Slug)
+
+	w, second := sendArticle(t, router, "POST", "/articles", `{"name":"Привет мир!"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "privet-mir-2", *second.Slug)
+
+	w, third := sendArticle(t, router, "POST", "/articles", `{"name":"Привет... мир"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "privet-mir-3", *third.Slug)
+
+	// Editing the body keeps the suffixed slug
+	w, second = sendArticle(t, router, "PUT", "/articles/2", `{"name":"Привет мир!","body":"текст"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "privet-mir-2", *second.Slug)
+
+	// Renaming regenerates the slug and the old one stays reserved
+	w, first = sendArticle(t, router, "PUT", "/articles/1", `{"name":"Пока, мир"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "poka-mir", *first.Slug)
+
+	w, fourth := sendArticle(t, router, "POST", "/articles", `{"name":"Привет, мир?"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "privet-mir-4", *fourth.Slug)
+
+	w, _ = sendArticle(t, router, "POST", "/articles", `{"name":"Другое","slug":"privet-mir"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// An article can take its own former slug back
+	w, first = sendArticle(t, router, "PUT", "/articles/1", `{"name":"Пока, мир","slug":"privet-mir"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "privet-mir", *first.Slug)
+}
+
+func TestGetArticleBySlug(t *testing.T) {
+	router := setupTestRouter(t)
+
+	w, _ := sendArticle(t, router, "POST", "/articles", `{"name":"Старое имя"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w, _ = sendArticle(t, router, "PUT", "/articles/1", `{"name":"Новое имя"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w, _ = sendArticle(t, router, "PUT", "/articles/1", `{"name":"Третье имя"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	tests := []struct {
+		name             string
+		url              string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{name: "current slug", url: "/articles/by-slug/trete-imya", expectedStatus: http.StatusOK},
+		{name: "first former slug", url: "/articles/by-slug/staroe-imya", expectedStatus: http.StatusMovedPermanently, expectedLocation: "/articles/by-slug/trete-imya"},
+		{name: "second former slug", url: "/articles/by-slug/novoe-imya", expectedStatus: http.StatusMovedPermanently, expectedLocation: "/articles/by-slug/trete-imya"},
+		{name: "query string is kept", url: "/articles/by-slug/staroe-imya?x=1&y=2", expectedStatus: http.StatusMovedPermanently, expectedLocation: "/articles/by-slug/trete-imya?x=1&y=2"},
+		{name: "unknown slug", url: "/articles/by-slug/nichego", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"name":"Третье имя"`)
+			}
+		})
+	}
+}
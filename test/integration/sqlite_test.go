@@ -0,0 +1,145 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	migrate "github.com/hexlet-components/go-gin-example/db"
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSQLiteFile migrates a new database file and opens it the way the
+// server does
+func setupSQLiteFile(t *testing.T, opts migrate.SQLiteOptions) (string, *migrate.Pools) {
+	t.Helper()
+
+	migrationOpts := migrate.DefaultMigrationOptions()
+	migrationOpts.DBFile = filepath.Join(t.TempDir(), "articles.db")
+	migrationOpts.Out = io.Discard
+	require.NoError(t, migrate.MigrateUp(migrationOpts))
+
+	return migrationOpts.DBFile, openSQLiteFile(t, migrationOpts.DBFile, opts)
+}
+
+func openSQLiteFile(t *testing.T, path string, opts migrate.SQLiteOptions) *migrate.Pools {
+	t.Helper()
+	pools, err := migrate.OpenSQLite(path, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { pools.Close() })
+	return pools
+}
+
+func TestSQLiteConnections(t *testing.T) {
+	opts := migrate.DefaultSQLiteOptions()
+	opts.MaxOpenConns = 3
+	_, pools := setupSQLiteFile(t, opts)
+
+	tests := []struct {
+		name     string
+		pool     string
+		pragma   string
+		expected string
+	}{
+		{name: "write-ahead log", pool: "writer", pragma: "journal_mode", expected: "wal"},
+		{name: "busy timeout", pool: "writer", pragma: "busy_timeout", expected: "5000"},
+		{name: "foreign keys", pool: "writer", pragma: "foreign_keys", expected: "1"},
+		{name: "synchronous normal", pool: "writer", pragma: "synchronous", expected: "1"},
+		{name: "writer can write", pool: "writer", pragma: "query_only", expected: "0"},
+		{name: "reader busy timeout", pool: "reader", pragma: "busy_timeout", expected: "5000"},
+		{name: "reader foreign keys", pool: "reader", pragma: "foreign_keys", expected: "1"},
+		{name: "reader is query-only", pool: "reader", pragma: "query_only", expected: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := pools.Writer
+			if tt.pool == "reader" {
+				database = pools.Reader
+			}
+			var value string
+			require.NoError(t, database.QueryRow("PRAGMA "+tt.pragma).Scan(&value))
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+
+	assert.Equal(t, 1, pools.Writer.Stats().MaxOpenConnections)
+	assert.Equal(t, 3, pools.Reader.Stats().MaxOpenConnections)
+
+	_, err := pools.Reader.Exec("DELETE FROM articles")
+	assert.Error(t, err, "readers cannot write")
+}
+
+func TestSQLiteForeignKeysCascade(t *testing.T) {
+	_, pools := setupSQLiteFile(t, migrate.DefaultSQLiteOptions())
+	s := store.New(migrate.DialectSQLite, pools, nil)
+
+	article, err := s.CreateArticle(t.Context(), articleParams("alpha"))
+	require.NoError(t, err)
+	require.NoError(t, s.CreateRevision(t.Context(), db.CreateRevisionParams{
+		ArticleID: article.ID, Rev: 1, Name: "alpha", Status: "draft", CreatedAt: time.Now().UTC(),
+	}))
+	_, err = s.DeleteArticle(t.Context(), db.DeleteArticleParams{ID: article.ID})
+	require.NoError(t, err)
+
+	revisions, err := s.ListRevisions(t.Context(), db.ListRevisionsParams{ArticleID: article.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	err = s.CreateRevision(t.Context(), db.CreateRevisionParams{ArticleID: 42, Rev: 1, Name: "ghost", Status: "draft"})
+	assert.ErrorContains(t, err, "FOREIGN KEY constraint failed")
+}
+
+// TestSQLiteConcurrentWrites writes from many goroutines through two sets
+// of pools on one file, as the server and a CLI command would, while others
+// read. None of them may fail with "database is locked".
+func TestSQLiteConcurrentWrites(t *testing.T) {
+	path, pools := setupSQLiteFile(t, migrate.DefaultSQLiteOptions())
+	stores := []store.ArticleStore{
+		store.New(migrate.DialectSQLite, pools, nil),
+		store.New(migrate.DialectSQLite, openSQLiteFile(t, path, migrate.DefaultSQLiteOptions()), nil),
+	}
+
+	const writers, writes = 8, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writers*writes+writers)
+
+	for w := range writers {
+		s := stores[w%len(stores)]
+		wg.Go(func() {
+			for i := range writes {
+				err := s.InTx(t.Context(), func(tx store.ArticleStore) error {
+					article, err := tx.CreateArticle(t.Context(), articleParams(fmt.Sprintf("article %d-%d", w, i)))
+					if err != nil {
+						return err
+					}
+					return tx.CreateRevision(t.Context(), db.CreateRevisionParams{
+						ArticleID: article.ID, Rev: 1, Name: article.Name, Status: article.Status, CreatedAt: article.CreatedAt,
+					})
+				})
+				if err != nil {
+					errs <- err
+				}
+				if _, err := s.ListArticlesPage(t.Context(), db.ListArticlesPageParams{Limit: 10}); err != nil {
+					errs <- err
+				}
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	articles, err := stores[0].ListArticles(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, articles, writers*writes)
+}
@@ -0,0 +1,559 @@
+package integration
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/hexlet-components/go-gin-example/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// articleStores opens an empty store of every implementation. The
+// PostgreSQL one is skipped unless TEST_POSTGRES_DSN is set.
+var articleStores = []struct {
+	name string
+	open func(t *testing.T) store.ArticleStore
+}{
+	{name: "memory", open: func(t *testing.T) store.ArticleStore { return store.NewMemory() }},
+	{name: "sqlite", open: func(t *testing.T) store.ArticleStore { return store.NewSQLite(setupTestDB(t)) }},
+	{name: "postgres", open: func(t *testing.T) store.ArticleStore {
+		database, _ := setupPostgres(t)
+		return store.NewPostgres(database)
+	}},
+}
+
+// storeConformance is what every ArticleStore must do, each case gets an
+// empty store
+var storeConformance = []struct {
+	name string
+	run  func(t *testing.T, s store.ArticleStore)
+}{
+	{name: "create and get", run: testStoreCreate},
+	{name: "unique fields", run: testStoreUnique},
+	{name: "update", run: testStoreUpdate},
+	{name: "patch", run: testStorePatch},
+	{name: "trash", run: testStoreTrash},
+	{name: "delete", run: testStoreDelete},
+	{name: "slug history", run: testStoreSlugs},
+	{name: "revisions", run: testStoreRevisions},
+	{name: "find articles", run: testStoreFind},
+	{name: "transactions", run: testStoreTx},
+	{name: "search", run: testStoreSearch},
+}
+
+func TestArticleStores(t *testing.T) {
+	for _, impl := range articleStores {
+		t.Run(impl.name, func(t *testing.T) {
+			for _, tt := range storeConformance {
+				t.Run(tt.name, func(t *testing.T) {
+					tt.run(t, impl.open(t))
+				})
+			}
+		})
+	}
+}
+
+var storeNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// createStoreArticle creates a draft or published article named name with
+// an optional slug
+func createStoreArticle(t *testing.T, s store.ArticleStore, name, status string, slug *string) db.Article {
+	t.Helper()
+	params := articleParams(name)
+	params.Body = "Body of " + name
+	params.Status = status
+	params.Slug = slug
+	params.Now = storeNow
+	article, err := s.CreateArticle(t.Context(), params)
+	require.NoError(t, err)
+	return article
+}
+
+func TestRouterWithMemoryStore(t *testing.T) {
+	router := handlers.SetupRouter(store.NewMemory(), routerConfig)
+
+	w, article := sendArticle(t, router, "POST", "/articles", `{"name":"Введение в Go","status":"published"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "vvedenie-v-go", *article.Slug)
+
+	w, _ = sendArticle(t, router, "POST", "/articles", `{"name":"введение в go"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, page := getPage(t, router, "/articles?status=published")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []int64{1}, ids(page.Items))
+
+	w = conditionalRequest(router, "GET", "/diagnostics", "", "", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"schema"`, "there is no database to report on")
+}
+
+func testStoreCreate(t *testing.T, s store.ArticleStore) {
+	created := createStoreArticle(t, s, "alpha", "published", new("alpha"))
+	assert.Equal(t, int64(1), created.ID)
+	assert.Equal(t, int64(1), created.Version)
+	require.NotNil(t, created.PublishedAt)
+	assert.True(t, storeNow.Equal(*created.PublishedAt))
+
+	article, err := s.GetArticle(t.Context(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", article.Name)
+	assert.Equal(t, "Body of alpha", article.Body)
+	assert.Equal(t, "alpha", *article.Slug)
+
+	article, err = s.GetArticleBySlug(t.Context(), "alpha")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, article.ID)
+
+	draft := createStoreArticle(t, s, "beta", "draft", nil)
+	assert.Nil(t, draft.PublishedAt)
+	assert.Nil(t, draft.Slug)
+
+	articles, err := s.ListArticles(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(articles))
+
+	_, err = s.GetArticle(t.Context(), 42)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = s.GetArticleBySlug(t.Context(), "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testStoreUnique(t *testing.T, s store.ArticleStore) {
+	createStoreArticle(t, s, "Go", "draft", new("go"))
+
+	tests := []struct {
+		name          string
+		articleName   string
+		slug          *string
+		expectedField string
+	}{
+		{name: "same name in another case", articleName: "GO", slug: new("other"), expectedField: "name"},
+		{name: "taken slug", articleName: "Other", slug: new("go"), expectedField: "slug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := articleParams(tt.articleName)
+			params.Slug = tt.slug
+			_, err := s.CreateArticle(t.Context(), params)
+			field, ok := store.UniqueViolation(err)
+			require.True(t, ok, "expected a unique violation, got %v", err)
+			assert.Equal(t, tt.expectedField, field)
+			assert.True(t, store.IsUniqueViolation(err))
+		})
+	}
+
+	assert.False(t, store.IsUniqueViolation(sql.ErrNoRows))
+}
+
+func testStoreUpdate(t *testing.T, s store.ArticleStore) {
+	createStoreArticle(t, s, "alpha", "draft", nil)
+	later := storeNow.Add(time.Hour)
+
+	params := db.UpdateArticleParams{
+		ID:      1,
+		Version: 1,
+		Name:    "Alpha",
+		NameKey: textnorm.NameKey("Alpha"),
+		Body:    "Updated",
+		Slug:    new("alpha"),
+		Status:  "published",
+		Now:     later,
+	}
+	updated, err := s.UpdateArticle(t.Context(), params)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+	assert.Equal(t, "Alpha", updated.Name)
+	assert.Equal(t, "Updated", updated.Body)
+	assert.True(t, later.Equal(updated.UpdatedAt))
+	require.NotNil(t, updated.PublishedAt)
+	assert.True(t, later.Equal(*updated.PublishedAt))
+
+	_, err = s.UpdateArticle(t.Context(), params)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "stale version")
+
+	params.Version = 2
+	params.Status = "draft"
+	updated, err = s.UpdateArticle(t.Context(), params)
+	require.NoError(t, err)
+	assert.Nil(t, updated.PublishedAt)
+}
+
+func testStorePatch(t *testing.T, s store.ArticleStore) {
+	createStoreArticle(t, s, "alpha", "draft", new("alpha"))
+	createStoreArticle(t, s, "beta", "draft", nil)
+
+	patched, err := s.PatchArticle(t.Context(), db.PatchArticleParams{
+		ID:      1,
+		Version: 1,
+		Body:    sql.NullString{String: "Patched", Valid: true},
+		Now:     storeNow,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", patched.Name)
+	assert.Equal(t, "Patched", patched.Body)
+	assert.Equal(t, "alpha", *patched.Slug)
+	assert.Equal(t, int64(2), patched.Version)
+
+	_, err = s.PatchArticle(t.Context(), db.PatchArticleParams{
+		ID:      2,
+		Version: 1,
+		Name:    sql.NullString{String: "ALPHA", Valid: true},
+		NameKey: sql.NullString{String: textnorm.NameKey("ALPHA"), Valid: true},
+		Now:     storeNow,
+	})
+	field, ok := store.UniqueViolation(err)
+	require.True(t, ok, "expected a unique violation, got %v", err)
+	assert.Equal(t, "name", field)
+
+	_, err = s.PatchArticle(t.Context(), db.PatchArticleParams{ID: 1, Version: 1, Now: storeNow})
+	assert.ErrorIs(t, err, sql.ErrNoRows, "stale version")
+}
+
+func testStoreTrash(t *testing.T, s store.ArticleStore) {
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		createStoreArticle(t, s, name, "draft", nil)
+	}
+
+	for i, id := range []int64{1, 2} {
+		deletedAt := storeNow.Add(time.Duration(i) * time.Hour)
+		n, err := s.SoftDeleteArticle(t.Context(), db.SoftDeleteArticleParams{ID: id, Now: &deletedAt})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	}
+
+	n, err := s.SoftDeleteArticle(t.Context(), db.SoftDeleteArticleParams{
+		ID:      3,
+		Version: sql.NullInt64{Int64: 5, Valid: true},
+		Now:     &storeNow,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, n, "stale version")
+
+	_, err = s.GetArticle(t.Context(), 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	deleted, err := s.GetArticleWithDeleted(t.Context(), 1)
+	require.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+	assert.Equal(t, int64(2), deleted.Version)
+
+	trash, err := s.ListDeletedArticles(t.Context(), db.ListDeletedArticlesParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, ids(trash), "most recently deleted first")
+
+	restored, err := s.RestoreArticle(t.Context(), 2)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	_, err = s.RestoreArticle(t.Context(), 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "not in the trash")
+
+	live, err := s.ListArticlesPage(t.Context(), db.ListArticlesPageParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, ids(live))
+
+	before := storeNow.Add(time.Minute)
+	purged, err := s.PurgeDeletedArticles(t.Context(), &before)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	_, err = s.GetArticleWithDeleted(t.Context(), 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testStoreDelete(t *testing.T, s store.ArticleStore) {
+	createStoreArticle(t, s, "alpha", "draft", nil)
+
+	n, err := s.DeleteArticle(t.Context(), db.DeleteArticleParams{ID: 1, Version: sql.NullInt64{Int64: 2, Valid: true}})
+	require.NoError(t, err)
+	assert.Zero(t, n, "stale version")
+
+	n, err = s.DeleteArticle(t.Context(), db.DeleteArticleParams{ID: 1, Version: sql.NullInt64{Int64: 1, Valid: true}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = s.GetArticleWithDeleted(t.Context(), 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	n, err = s.DeleteArticle(t.Context(), db.DeleteArticleParams{ID: 1})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func testStoreSlugs(t *testing.T, s store.ArticleStore) {
+	createStoreArticle(t, s, "alpha", "draft", new("new"))
+	createStoreArticle(t, s, "beta", "draft", new("new-2"))
+	require.NoError(t, s.AddSlugHistory(t.Context(), db.AddSlugHistoryParams{Slug: "old", ArticleID: 1, CreatedAt: storeNow}))
+
+	current, err := s.GetCurrentSlug(t.Context(), "old")
+	require.NoError(t, err)
+	assert.Equal(t, "new", *current)
+
+	tests := []struct {
+		name     string
+		params   db.ListTakenSlugsParams
+		expected []string
+	}{
+		{name: "current slugs of others", params: db.ListTakenSlugsParams{Base: "new", ArticleID: 3}, expected: []string{"new", "new-2"}},
+		{name: "own slugs are free", params: db.ListTakenSlugsParams{Base: "new", ArticleID: 1}, expected: []string{"new-2"}},
+		{name: "history of others", params: db.ListTakenSlugsParams{Base: "old", ArticleID: 2}, expected: []string{"old"}},
+		{name: "unrelated base", params: db.ListTakenSlugsParams{Base: "ne", ArticleID: 3}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taken, err := s.ListTakenSlugs(t.Context(), tt.params)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expected, taken)
+		})
+	}
+
+	require.NoError(t, s.DeleteSlugHistory(t.Context(), "old"))
+	_, err = s.GetCurrentSlug(t.Context(), "old")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testStoreRevisions(t *testing.T, s store.ArticleStore) {
+	createStoreArticle(t, s, "alpha", "draft", nil)
+
+	for rev := range int64(2) {
+		err := s.CreateRevision(t.Context(), db.CreateRevisionParams{
+			ArticleID: 1,
+			Rev:       rev + 1,
+			Name:      "alpha",
+			Body:      "Body",
+			Status:    "draft",
+			CreatedAt: storeNow,
+		})
+		require.NoError(t, err)
+	}
+
+	err := s.CreateRevision(t.Context(), db.CreateRevisionParams{ArticleID: 1, Rev: 1, Name: "alpha", Status: "draft", CreatedAt: storeNow})
+	assert.True(t, store.IsUniqueViolation(err), "expected a unique violation, got %v", err)
+
+	revisions, err := s.ListRevisions(t.Context(), db.ListRevisionsParams{ArticleID: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, int64(2), revisions[0].Rev, "newest first")
+
+	revisions, err = s.ListRevisions(t.Context(), db.ListRevisionsParams{ArticleID: 1, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, int64(1), revisions[0].Rev)
+
+	revision, err := s.GetRevision(t.Context(), db.GetRevisionParams{ArticleID: 1, Rev: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "Body", revision.Body)
+
+	_, err = s.GetRevision(t.Context(), db.GetRevisionParams{ArticleID: 1, Rev: 3})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testStoreFind(t *testing.T, s store.ArticleStore) {
+	createStoreArticle(t, s, "alpha", "published", new("alpha"))
+	createStoreArticle(t, s, "beta", "draft", nil)
+	createStoreArticle(t, s, "gamma", "published", new("gamma"))
+	createStoreArticle(t, s, "delta", "published", nil)
+	_, err := s.SoftDeleteArticle(t.Context(), db.SoftDeleteArticleParams{ID: 4, Now: &storeNow})
+	require.NoError(t, err)
+
+	byID := []store.SortKey{{Field: "id"}}
+	tests := []struct {
+		name        string
+		query       store.ArticleQuery
+		expectedIDs []int64
+	}{
+		{name: "everything outside the trash", query: store.ArticleQuery{Sort: byID}, expectedIDs: []int64{1, 2, 3}},
+		{
+			name:        "equal",
+			query:       store.ArticleQuery{Filters: []store.Filter{{Field: "status", Op: store.OpEq, Values: []any{"published"}}}, Sort: byID},
+			expectedIDs: []int64{1, 3},
+		},
+		{
+			name:        "contains ignores case",
+			query:       store.ArticleQuery{Filters: []store.Filter{{Field: "name", Op: store.OpContains, Values: []any{"MM"}}}, Sort: byID},
+			expectedIDs: []int64{3},
+		},
+		{
+			name:        "prefix ignores case",
+			query:       store.ArticleQuery{Filters: []store.Filter{{Field: "name", Op: store.OpPrefix, Values: []any{"B"}}}, Sort: byID},
+			expectedIDs: []int64{2},
+		},
+		{
+			name:        "like wildcards are literal",
+			query:       store.ArticleQuery{Filters: []store.Filter{{Field: "name", Op: store.OpContains, Values: []any{"%"}}}, Sort: byID},
+			expectedIDs: []int64{},
+		},
+		{
+			name:        "in",
+			query:       store.ArticleQuery{Filters: []store.Filter{{Field: "id", Op: store.OpIn, Values: []any{int64(1), int64(3), int64(4)}}}, Sort: byID},
+			expectedIDs: []int64{1, 3},
+		},
+		{
+			name:        "null never matches",
+			query:       store.ArticleQuery{Filters: []store.Filter{{Field: "slug", Op: store.OpNe, Values: []any{"alpha"}}}, Sort: byID},
+			expectedIDs: []int64{3},
+		},
+		{
+			name: "time range",
+			query: store.ArticleQuery{Filters: []store.Filter{
+				{Field: "created_at", Op: store.OpGte, Values: []any{storeNow}},
+				{Field: "created_at", Op: store.OpLt, Values: []any{storeNow.Add(time.Second)}},
+			}, Sort: byID},
+			expectedIDs: []int64{1, 2, 3},
+		},
+		{
+			name:        "descending",
+			query:       store.ArticleQuery{Filters: []store.Filter{{Field: "id", Op: store.OpGt, Values: []any{int64(1)}}}, Sort: []store.SortKey{{Field: "id", Desc: true}}},
+			expectedIDs: []int64{3, 2},
+		},
+		{
+			name:        "several keys",
+			query:       store.ArticleQuery{Sort: []store.SortKey{{Field: "status", Desc: true}, {Field: "id"}}},
+			expectedIDs: []int64{1, 3, 2},
+		},
+		{name: "limit and offset", query: store.ArticleQuery{Sort: byID, Limit: 1, Offset: 1}, expectedIDs: []int64{2}},
+		{
+			name:        "after ignores offset",
+			query:       store.ArticleQuery{Sort: []store.SortKey{{Field: "status", Desc: true}, {Field: "id"}}, After: []any{"published", int64(1)}, Offset: 5},
+			expectedIDs: []int64{3, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.query.Limit == 0 {
+				tt.query.Limit = 10
+			}
+			articles, err := s.FindArticles(t.Context(), tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, ids(articles))
+		})
+	}
+
+	// LIKE alone only folds ASCII case
+	createStoreArticle(t, s, "Привет Мир", "draft", nil)
+	cyrillic := []struct {
+		name        string
+		filter      store.Filter
+		expectedIDs []int64
+	}{
+		{name: "contains lowercase", filter: store.Filter{Field: "name", Op: store.OpContains, Values: []any{"привет"}}, expectedIDs: []int64{5}},
+		{name: "contains uppercase", filter: store.Filter{Field: "name", Op: store.OpContains, Values: []any{"ПРИВЕТ МИР"}}, expectedIDs: []int64{5}},
+		{name: "prefix mixed case", filter: store.Filter{Field: "name", Op: store.OpPrefix, Values: []any{"пРИВ"}}, expectedIDs: []int64{5}},
+		{name: "prefix elsewhere", filter: store.Filter{Field: "name", Op: store.OpPrefix, Values: []any{"мир"}}, expectedIDs: []int64{}},
+	}
+	for _, tt := range cyrillic {
+		t.Run("cyrillic "+tt.name, func(t *testing.T) {
+			articles, err := s.FindArticles(t.Context(), store.ArticleQuery{Filters: []store.Filter{tt.filter}, Sort: byID, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, ids(articles))
+		})
+	}
+
+	invalid := []store.ArticleQuery{
+		{Filters: []store.Filter{{Field: "name_key", Op: store.OpEq, Values: []any{"alpha"}}}},
+		{Filters: []store.Filter{{Field: "name", Op: "like", Values: []any{"a%"}}}},
+		{Filters: []store.Filter{{Field: "name", Op: store.OpEq}}},
+		{Sort: []store.SortKey{{Field: "version"}}},
+		{Sort: byID, After: []any{int64(1), int64(2)}},
+	}
+	for _, q := range invalid {
+		_, err := s.FindArticles(t.Context(), q)
+		assert.Error(t, err, "%+v", q)
+	}
+}
+
+func testStoreTx(t *testing.T, s store.ArticleStore) {
+	errRollback := errors.New("rollback")
+	err := s.InTx(t.Context(), func(tx store.ArticleStore) error {
+		createStoreArticle(t, tx, "alpha", "draft", nil)
+		articles, err := tx.ListArticles(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, articles, 1, "the transaction sees its own writes")
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	articles, err := s.ListArticles(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, articles, "rolled back")
+
+	err = s.InTx(t.Context(), func(tx store.ArticleStore) error {
+		_, err := tx.CreateArticle(t.Context(), articleParams("beta"))
+		return err
+	})
+	require.NoError(t, err)
+
+	articles, err = s.ListArticles(t.Context())
+	require.NoError(t, err)
+	require.Len(t, articles, 1, "committed")
+	assert.Equal(t, "beta", articles[0].Name)
+}
+
+func testStoreSearch(t *testing.T, s store.ArticleStore) {
+	for _, a := range []struct{ name, body string }{
+		{"Введение в Go", "Горутины и каналы"},
+		{"Ёлка на Rust", "Аллокаторы и владение"},
+		{"Каналы в Go", "Буферизованные каналы и каналы без буфера"},
+	} {
+		params := articleParams(a.name)
+		params.Body = a.body
+		_, err := s.CreateArticle(t.Context(), params)
+		require.NoError(t, err)
+	}
+
+	_, err := s.SearchArticles(t.Context(), "go", 10, 0)
+	if errors.Is(err, store.ErrSearchUnavailable) {
+		t.Skip("the store has no full-text index")
+	}
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		q               string
+		expectedIDs     []int64
+		expectedSnippet string
+	}{
+		{name: "ё is folded", q: "елка", expectedIDs: []int64{2}, expectedSnippet: "<mark>Елка</mark>"},
+		{name: "last word is a prefix", q: "горут", expectedIDs: []int64{1}, expectedSnippet: "<mark>Горутины</mark>"},
+		{name: "every word must match", q: "go каналы", expectedIDs: []int64{3, 1}},
+		{name: "no match", q: "python", expectedIDs: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := s.SearchArticles(t.Context(), tt.q, 10, 0)
+			require.NoError(t, err)
+			ids := []int64{}
+			for _, r := range results {
+				ids = append(ids, r.ID)
+				assert.Positive(t, r.Rank)
+			}
+			assert.Equal(t, tt.expectedIDs, ids, "best match first")
+			if tt.expectedSnippet != "" {
+				// Stores differ in whether snippets show ё as written
+				snippet := strings.NewReplacer("ё", "е", "Ё", "Е").Replace(results[0].Snippet)
+				assert.Contains(t, snippet, tt.expectedSnippet)
+			}
+		})
+	}
+
+	_, err = s.SoftDeleteArticle(t.Context(), db.SoftDeleteArticleParams{ID: 2, Now: &storeNow})
+	require.NoError(t, err)
+	results, err := s.SearchArticles(t.Context(), "rust", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, results, "the trash is not searched")
+
+	results, err = s.SearchArticles(t.Context(), "каналы", 1, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(1), results[0].ID)
+}
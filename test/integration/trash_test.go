@@ -0,0 +1,188 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deleteArticle(router http.Handler, url, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("DELETE", url, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSoftDeleteHidesArticle(t *testing.T) {
+	router, queries := setupTestRouterWithQueries(t)
+	for _, name := range []string{"Kept", "Deleted"} {
+		w, _ := sendArticle(t, router, "POST", "/articles", `{"name":"`+name+`"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	w := deleteArticle(router, "/articles/2", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, url := range []string{"/articles/2", "/articles/by-slug/deleted"} {
+		w, _ = sendArticle(t, router, "GET", url, "")
+		assert.Equal(t, http.StatusNotFound, w.Code, url)
+	}
+
+	w, _ = sendArticle(t, router, "PUT", "/articles/2", `{"name":"Deleted"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, url := range []string{"/articles", "/articles?sort=-name"} {
+		_, page := getPage(t, router, url)
+		assert.Equal(t, []int64{1}, ids(page.Items), url)
+	}
+
+	// Names stay reserved while the article is in the trash
+	w, _ = sendArticle(t, router, "POST", "/articles", `{"name":"Deleted"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	deleted, err := queries.GetArticleWithDeleted(t.Context(), 2)
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+	assert.Equal(t, int64(2), deleted.Version)
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	router, queries := setupTestRouterWithQueries(t)
+	for _, name := range []string{"First", "Second", "Third"} {
+		_, err := queries.CreateArticle(t.Context(), articleParams(name))
+		require.NoError(t, err)
+	}
+	for _, url := range []string{"/articles/1", "/articles/3"} {
+		require.Equal(t, http.StatusNoContent, deleteArticle(router, url, "").Code)
+	}
+
+	_, page := getPage(t, router, "/articles/trash")
+	assert.Equal(t, []int64{3, 1}, ids(page.Items), "most recently deleted first")
+	assertArticleJSON(t,
+		`{"id":3,"name":"Third","body":"","slug":null,"status":"draft","created_at":"<time>","updated_at":"<time>","published_at":null,"version":2,"deleted_at":"<time>"}`,
+		string(mustMarshal(t, page.Items[0])))
+
+	w, page := getPage(t, router, "/articles/trash?limit=1")
+	assert.Equal(t, []int64{3}, ids(page.Items))
+	assert.Contains(t, w.Header().Get("Link"), `rel="next"`)
+
+	w, restored := sendArticle(t, router, "POST", "/articles/1/restore", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, int64(3), restored.Version)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	_, page = getPage(t, router, "/articles")
+	assert.Equal(t, []int64{1, 2}, ids(page.Items))
+	_, page = getPage(t, router, "/articles/trash")
+	assert.Equal(t, []int64{3}, ids(page.Items))
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedType   string
+	}{
+		{name: "article outside the trash", url: "/articles/2/restore", expectedStatus: http.StatusConflict, expectedType: "article-not-in-trash"},
+		{name: "missing article", url: "/articles/42/restore", expectedStatus: http.StatusNotFound, expectedType: "article-not-found"},
+		{name: "invalid id", url: "/articles/abc/restore", expectedStatus: http.StatusBadRequest, expectedType: "invalid-id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := sendArticle(t, router, "POST", tt.url, "")
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), handlers.ProblemTypeBase+tt.expectedType)
+		})
+	}
+}
+
+func TestHardDelete(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		token          string
+		trashed        bool
+		expectedStatus int
+		expectedType   string
+		expectedGone   bool
+	}{
+		{name: "admin deletes for good", url: "/articles/1?hard=true", token: adminToken, expectedStatus: http.StatusNoContent, expectedGone: true},
+		{name: "admin deletes from the trash", url: "/articles/1?hard=true", token: adminToken, trashed: true, expectedStatus: http.StatusNoContent, expectedGone: true},
+		{name: "hard=false is a soft delete", url: "/articles/1?hard=false", expectedStatus: http.StatusNoContent},
+		{name: "without token", url: "/articles/1?hard=true", expectedStatus: http.StatusForbidden, expectedType: "admin-required"},
+		{name: "wrong token", url: "/articles/1?hard=true", token: "guess", expectedStatus: http.StatusForbidden, expectedType: "admin-required"},
+		{name: "invalid flag", url: "/articles/1?hard=yes", token: adminToken, expectedStatus: http.StatusBadRequest, expectedType: "invalid-boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, queries := setupTestRouterWithQueries(t)
+			_, err := queries.CreateArticle(t.Context(), articleParams("Test Article"))
+			require.NoError(t, err)
+			if tt.trashed {
+				require.Equal(t, http.StatusNoContent, deleteArticle(router, "/articles/1", "").Code)
+			}
+
+			w := deleteArticle(router, tt.url, tt.token)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				assert.Contains(t, w.Body.String(), handlers.ProblemTypeBase+tt.expectedType)
+			}
+			_, err = queries.GetArticleWithDeleted(t.Context(), 1)
+			assert.Equal(t, tt.expectedGone, err != nil)
+		})
+	}
+}
+
+func TestHardDeleteDisabledWithoutToken(t *testing.T) {
+	router := newRouter(setupTestDB(t), handlers.RouterConfig{})
+
+	w := deleteArticle(router, "/articles/1?hard=true", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestPurgeDeletedArticles(t *testing.T) {
+	queries, _ := setupTestQueries(t)
+	for _, name := range []string{"Old", "Recent", "Live"} {
+		_, err := queries.CreateArticle(t.Context(), articleParams(name))
+		require.NoError(t, err)
+	}
+
+	now := time.Now().UTC()
+	old := now.Add(-40 * 24 * time.Hour)
+	for id, at := range map[int64]*time.Time{1: &old, 2: &now} {
+		_, err := queries.SoftDeleteArticle(t.Context(), db.SoftDeleteArticleParams{ID: id, Now: at})
+		require.NoError(t, err)
+	}
+
+	before := now.Add(-30 * 24 * time.Hour)
+	purged, err := queries.PurgeDeletedArticles(t.Context(), &before)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	_, err = queries.GetArticleWithDeleted(t.Context(), 1)
+	assert.Error(t, err)
+	for _, id := range []int64{2, 3} {
+		_, err = queries.GetArticleWithDeleted(t.Context(), id)
+		assert.NoError(t, err)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -2,16 +2,24 @@ package integration
 
 import (
 	"database/sql"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/hexlet-components/go-gin-example/db/generated"
+	"github.com/hexlet-components/go-gin-example/db/migrations"
 	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/hexlet-components/go-gin-example/internal/textnorm"
+	"github.com/hexlet-components/go-gin-example/store"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
 )
 
-const migrationsDir = "../../db/migrations"
+const adminToken = "test-admin-token"
+
+var routerConfig = handlers.RouterConfig{AdminToken: adminToken}
 
 func setupTestDB(t *testing.T) *sql.DB {
 	t.Helper()
@@ -21,8 +29,10 @@ func setupTestDB(t *testing.T) *sql.DB {
 		t.Fatalf("failed to open test DB: %v", err)
 		return nil
 	}
+	// Every connection to ":memory:" gets its own empty database
+	testDB.SetMaxOpenConns(1)
 
-	if err := applyMigrations(testDB, migrationsDir); err != nil {
+	if err := applyMigrations(testDB); err != nil {
 		t.Fatalf("failed to apply migrations: %v", err)
 		return nil
 	}
@@ -30,9 +40,18 @@ func setupTestDB(t *testing.T) *sql.DB {
 	return testDB
 }
 
-func applyMigrations(database *sql.DB, migrationsDir string) error {
+// applyMigrations runs the migrations embedded into the binary, the same
+// set the application uses
+func applyMigrations(database *sql.DB) error {
 	goose.SetDialect("sqlite3")
-	return goose.Up(database, migrationsDir)
+	goose.SetBaseFS(migrations.FS)
+	return goose.Up(database, ".")
+}
+
+// newRouter serves the SQLite database testDB with cfg
+func newRouter(testDB *sql.DB, cfg handlers.RouterConfig) *gin.Engine {
+	cfg.DB = testDB
+	return handlers.SetupRouter(store.NewSQLite(testDB), cfg)
 }
 
 func setupTestRouter(t *testing.T) *gin.Engine {
@@ -42,7 +61,7 @@ func setupTestRouter(t *testing.T) *gin.Engine {
 		t.Fatalf("failed to setup test DB")
 	}
 
-	return handlers.SetupRouter(testDB)
+	return newRouter(testDB, routerConfig)
 }
 
 func setupTestQueries(t *testing.T) (*db.Queries, *sql.DB) {
@@ -59,6 +78,51 @@ func setupTestQueries(t *testing.T) (*db.Queries, *sql.DB) {
 func setupTestRouterWithQueries(t *testing.T) (*gin.Engine, *db.Queries) {
 	t.Helper()
 	queries, testDB := setupTestQueries(t)
-	router := handlers.SetupRouter(testDB)
+	router := newRouter(testDB, routerConfig)
 	return router, queries
 }
+
+func articleParams(name string) db.CreateArticleParams {
+	return db.CreateArticleParams{
+		Name:    name,
+		NameKey: textnorm.NameKey(name),
+		Status:  "draft",
+		Now:     time.Now().UTC(),
+	}
+}
+
+// assertArticleJSON compares article JSON ignoring the exact timestamps: every
+// non-null timestamp is replaced with "<time>"
+func assertArticleJSON(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	var doc any
+	if err := json.Unmarshal([]byte(actual), &doc); err != nil {
+		t.Fatalf("invalid JSON %q: %v", actual, err)
+	}
+
+	var mask func(v any)
+	mask = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, value := range v {
+				switch key {
+				case "created_at", "updated_at", "published_at", "deleted_at":
+					if value != nil {
+						v[key] = "<time>"
+					}
+				default:
+					mask(value)
+				}
+			}
+		case []any:
+			for _, item := range v {
+				mask(item)
+			}
+		}
+	}
+	mask(doc)
+
+	masked, _ := json.Marshal(doc)
+	assert.JSONEq(t, expected, string(masked))
+}
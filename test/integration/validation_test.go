@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hexlet-components/go-gin-example/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrorDetails(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		acceptLanguage   string
+		expectedLanguage string
+		expected         []handlers.FieldViolation
+	}{
+		{
+			name:             "missing name in english by default",
+			body:             `{}`,
+			expectedLanguage: "en",
+			expected: []handlers.FieldViolation{
+				{Field: "name", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:             "missing name in russian",
+			body:             `{}`,
+			acceptLanguage:   "ru-RU,ru;q=0.9,en;q=0.8",
+			expectedLanguage: "ru",
+			expected: []handlers.FieldViolation{
+				{Field: "name", Rule: "required", Message: "обязательное поле"},
+			},
+		},
+		{
+			name:             "name too long in russian",
+			body:             `{"name":"` + strings.Repeat("я", 256) + `"}`,
+			acceptLanguage:   "ru",
+			expectedLanguage: "ru",
+			expected: []handlers.FieldViolation{
+				{Field: "name", Rule: "max", Param: "255", Message: "должно содержать не больше 255 символов"},
+			},
+		},
+		{
+			name:             "unsupported language falls back to english",
+			body:             `{"name":"` + strings.Repeat("a", 256) + `"}`,
+			acceptLanguage:   "de-DE",
+			expectedLanguage: "en",
+			expected: []handlers.FieldViolation{
+				{Field: "name", Rule: "max", Param: "255", Message: "must be at most 255 characters long"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter(t)
+
+			req, _ := http.NewRequest("POST", "/articles", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, tt.expectedLanguage, w.Header().Get("Content-Language"))
+
+			var problem handlers.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, handlers.ProblemTypeBase+"validation-failed", problem.Type)
+			assert.Equal(t, tt.expected, problem.Errors)
+		})
+	}
+}